@@ -26,7 +26,7 @@ func validateArguments() error {
 	// See if we have too many flags
 	numFlags := flag.NFlag()
 
-	// The platform and language flags never count
+	// The platform, language, source, format, color, width and pager flags never count
 	if *platform != "" {
 		numFlags--
 	}
@@ -35,6 +35,57 @@ func validateArguments() error {
 		numFlags--
 	}
 
+	if *source != "" {
+		numFlags--
+	}
+
+	if *format != "" {
+		numFlags--
+	}
+
+	if flag.CommandLine.Changed("color") {
+		numFlags--
+	}
+
+	if flag.CommandLine.Changed("width") {
+		numFlags--
+	}
+
+	if *noPager {
+		numFlags--
+	}
+
+	// Linting takes files instead of a command
+	if *lint {
+		if len(flag.Args()) == 0 {
+			return errors.New("missing argument: file")
+		}
+
+		if numFlags > 1 {
+			return errors.New("at most one flag can be set")
+		}
+
+		return nil
+	}
+
+	// Watching only makes sense while rendering
+	if *watch {
+		if *render == "" {
+			return errors.New("--watch can only be used with --render")
+		}
+
+		numFlags--
+	}
+
+	// Rendering takes additional files
+	if *render != "" {
+		if numFlags > 1 {
+			return errors.New("at most one flag can be set")
+		}
+
+		return nil
+	}
+
 	// If we don't have to do anything special, we need at least one command
 	if numFlags == 0 && len(flag.Args()) == 0 {
 		return errors.New("missing argument: command")
@@ -19,12 +19,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"time"
 
 	"github.com/elecprog/tldr/pages"
-	"github.com/elecprog/tldr/targets"
 	flag "github.com/spf13/pflag"
-	"go.etcd.io/bbolt"
 )
 
 // Run runs the tldr command
@@ -42,6 +39,19 @@ func Run() {
 		showHelp()
 	}
 
+	// Read the configuration file
+	err = loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "warning: failed to read configuration:", err)
+	}
+
+	// Make sure we know the requested format
+	err = validateFormat()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+
 	// Do we have to show help information
 	if *help {
 		showHelp()
@@ -60,9 +70,20 @@ func Run() {
 		return
 	}
 
-	// Are we asked to render a page?
+	// Are we asked to render pages?
 	if *render != "" {
-		pages.Render(*render)
+		if *watch {
+			watchFiles(append([]string{*render}, flag.Args()...))
+		}
+
+		startPager()
+		renderFiles(append([]string{*render}, flag.Args()...))
+		return
+	}
+
+	// Are we asked to check pages?
+	if *lint {
+		lintFiles(flag.Args())
 		return
 	}
 
@@ -79,8 +100,10 @@ func Run() {
 			os.Exit(1)
 		}
 
-		// We'll build the database
-		*update = true
+		// We'll build the database, unless we are importing it
+		if *imprt == "" {
+			*update = true
+		}
 	}
 
 	// Purge the database if requested
@@ -95,77 +118,99 @@ func Run() {
 		return
 	}
 
-	// We open/create the databse with a timeout of one second
-	// to not keep on attempting if there is something wrong.
-	// The database is opened as read only if we do not have
-	// to update it.
-	// A pages size of 128 seems to result in the smallest
-	// database size for our purposes, setting it so low
-	// would result in a major slowdown in large databases
-	// but ours is small.
-	db, err := bbolt.Open(dbPath, 0600,
-		&bbolt.Options{
-			Timeout:  1 * time.Second,
-			ReadOnly: !*update,
-			PageSize: 128,
-		})
+	// Import the database from a local source
+	if *imprt != "" {
+		skipped, err := pages.Import(dbPath, *imprt, getUpdateOptions())
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+
+		reportSkipped(skipped)
+		return
+	}
+
+	// Update the database if needed, this replaces the database
+	// file so it has to happen before we open it
+	if *update {
+		skipped, err := pages.Update(dbPath, getSources(), getUpdateOptions())
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+
+		reportSkipped(skipped)
+
+		// We might want to do other stuff though
+
+	} else if len(flag.Args()) > 0 {
+		// We are about to show a page, make sure it isn't outdated
+		autoUpdate(dbPath)
+	}
+
+	// Make sure we can read the database
+	checkSchema(dbPath)
+
+	// Open the database, all changes to it happen before this point
+	client, err := pages.Open(dbPath)
 
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "error: ", err)
-		os.Exit(1)
+		fail(err)
 	}
 
-	defer db.Close()
+	defer client.Close()
 
 	// Overide the operating system
 	if *platform != "" {
-		targets.OsDir = *platform
+		client.Platform = *platform
 	}
 
 	// Overide the language
 	if *language != "" {
-		targets.CurrentLanguage = *language
+		client.Language = *language
 	}
 
-	// Update the database if needed
-	if *update {
-		pages.Update(db)
-		// We might want to do other stuff though
-	}
+	// Long output is shown in a pager
+	startPager()
+	defer flushPager()
 
 	// Other actions
 	// Do we have to list commands?
 	if *list {
-		if targets.OsDir == "all" {
-			pages.ListAll(db)
-		} else {
-			pages.List(db)
-		}
+		printEntries(client.List())
 		return
 	}
 
 	// List platforms
 	if *listPlatforms {
-		pages.ListPlatforms(db)
+		printPlatforms(client.Platforms())
 		return
 	}
 
 	// List languages
 	if *listLanguages {
-		pages.ListLanguages(db)
+		printLanguages(client.Languages())
+		return
+	}
+
+	// Export the pages as man pages?
+	if *export != "" {
+		exportManPages(client, *export)
 		return
 	}
 
 	// Search?
 	if *search != "" {
-		pages.Search(db, *search)
+		printEntries(client.Search(*search))
 		return
 	}
 
 	// No, we simply want to see tldr pages :)
 	args := flag.Args()
 	if len(args) > 0 {
-		pages.Show(db, args)
+		showPage(client, args)
 	}
 
 }
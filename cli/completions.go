@@ -29,7 +29,7 @@ _tldr_completion()
 		fi
 	else
 		if [[ "${COMP_WORDS[$COMP_CWORD]}" == "-"* ]]; then
-			COMPREPLY=($(compgen -W "--help --language --list --platform --purge --render --search --update --version" -- ${COMP_WORDS[$COMP_CWORD]}))
+			COMPREPLY=($(compgen -W "--color --export-man --format --help --import --language --lint --list --no-pager --platform --purge --render --search --source --update --version --watch --width" -- ${COMP_WORDS[$COMP_CWORD]}))
 		else
 			COMPREPLY=($(tldr --search "^${COMP_WORDS[$COMP_CWORD]}" 2> /dev/null))
 		fi
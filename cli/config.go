@@ -0,0 +1,249 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elecprog/tldr/pages"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// config contains the settings read from the configuration file
+var config = make(map[string]string)
+
+// getConfigPath returns the path to the configuration file, or an empty
+// string if the system does not have a configuration directory.
+func getConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "tldr", "config")
+}
+
+// loadConfig reads the configuration file, which consists of lines of
+// the form `key = value`, empty lines and comments starting with `#`.
+// A missing configuration file is not an error.
+func loadConfig() error {
+	path := getConfigPath()
+	if path == "" || !pathExists(path) {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip empty lines and comments
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		split := strings.SplitN(line, "=", 2)
+		if len(split) != 2 {
+			// Ignore malformed lines
+			continue
+		}
+
+		config[strings.TrimSpace(split[0])] = strings.TrimSpace(split[1])
+	}
+
+	return scanner.Err()
+}
+
+// getSetting returns the value of a setting, a flag takes precedence over
+// the environment variable which takes precedence over the configuration
+// file. If none of these are set the default value is returned.
+func getSetting(flagValue, env, key, deflt string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+
+	if value := os.Getenv(env); value != "" {
+		return value
+	}
+
+	if value, ok := config[key]; ok && value != "" {
+		return value
+	}
+
+	return deflt
+}
+
+// getSources returns the locations to fetch pages from, in the order
+// in which they should be tried, the setting separates them with commas
+func getSources() []string {
+	setting := getSetting(*source, "TLDR_SOURCE", "source", "")
+
+	if setting == "" {
+		return pages.DefaultSources
+	}
+
+	var sources []string
+
+	for _, location := range strings.Split(setting, ",") {
+		if location = strings.TrimSpace(location); location != "" {
+			sources = append(sources, location)
+		}
+	}
+
+	return sources
+}
+
+// getUpdateOptions returns the configured options for fetching pages
+func getUpdateOptions() pages.UpdateOptions {
+	options := pages.UpdateOptions{
+		Checksum:  getSetting("", "TLDR_CHECKSUM", "checksum", ""),
+		Signature: getSetting("", "TLDR_SIGNATURE", "signature", ""),
+		PublicKey: getSetting("", "TLDR_PUBLIC_KEY", "public-key", ""),
+
+		MaxDownloadSize: getSize("TLDR_MAX_DOWNLOAD_SIZE", "max-download-size"),
+		MaxPageSize:     getSize("TLDR_MAX_PAGE_SIZE", "max-page-size"),
+		MaxEntries:      int(getSize("TLDR_MAX_ENTRIES", "max-entries")),
+
+		ConnectTimeout: getDuration("TLDR_CONNECT_TIMEOUT", "connect-timeout"),
+		Timeout:        getDuration("TLDR_TIMEOUT", "timeout"),
+		Retries:        getRetries(),
+	}
+
+	// Only show progress to humans
+	if terminal.IsTerminal(int(os.Stderr.Fd())) {
+		options.Progress = os.Stderr
+	}
+
+	return options
+}
+
+// getDuration returns a setting containing a duration, which is either
+// a number of seconds or a duration like 1m30s. Zero is returned if it
+// isn't set or not a valid duration.
+func getDuration(env, key string) time.Duration {
+	setting := getSetting("", env, key, "")
+
+	if setting == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(setting); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	duration, err := time.ParseDuration(setting)
+
+	if err != nil || duration < 0 {
+		fmt.Fprintln(os.Stderr, "warning: invalid "+key+" setting '"+setting+"', using the default")
+		return 0
+	}
+
+	return duration
+}
+
+// getRetries returns the number of times a download should be retried,
+// in the convention of pages.UpdateOptions
+func getRetries() int {
+	setting := getSetting("", "TLDR_RETRIES", "retries", "")
+
+	if setting == "" {
+		return 0
+	}
+
+	retries, err := strconv.Atoi(setting)
+
+	if err != nil || retries < 0 {
+		fmt.Fprintln(os.Stderr, "warning: invalid retries setting '"+setting+"', using the default")
+		return 0
+	}
+
+	// Zero means the default to pages
+	if retries == 0 {
+		return -1
+	}
+
+	return retries
+}
+
+// getSize returns a setting containing a size, which is a number optionally
+// followed by a K, M or G multiplier. Zero is returned if it isn't set
+// or not a valid size.
+func getSize(env, key string) int64 {
+	setting := getSetting("", env, key, "")
+
+	if setting == "" {
+		return 0
+	}
+
+	// Find the multiplier
+	multiplier := int64(1)
+	number := strings.TrimRight(strings.ToUpper(setting), "B")
+
+	switch {
+	case strings.HasSuffix(number, "K"):
+		multiplier = 1 << 10
+
+	case strings.HasSuffix(number, "M"):
+		multiplier = 1 << 20
+
+	case strings.HasSuffix(number, "G"):
+		multiplier = 1 << 30
+	}
+
+	if multiplier != 1 {
+		number = number[:len(number)-1]
+	}
+
+	size, err := strconv.ParseInt(strings.TrimSpace(number), 10, 64)
+
+	if err != nil || size < 0 {
+		fmt.Fprintln(os.Stderr, "warning: invalid "+key+" setting '"+setting+"', using the default")
+		return 0
+	}
+
+	return size * multiplier
+}
+
+// getTheme returns the theme used to show pages in a terminal, this
+// is nil if it isn't set or not a valid theme
+func getTheme() *pages.Theme {
+	setting := getSetting("", "TLDR_COLORS", "colors", "")
+
+	if setting == "" {
+		return nil
+	}
+
+	theme, err := pages.ParseTheme(setting)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "warning: invalid colors setting:", err)
+		return nil
+	}
+
+	return theme
+}
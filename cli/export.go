@@ -0,0 +1,86 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/elecprog/tldr/pages"
+)
+
+// exportManPages writes a man page named tldr-<command>.7 to the directory
+// for every command in the database. If a command has pages for several
+// platforms the one for the platform of the client is preferred, followed by
+// the common one. Pages are in the language of the client where translated.
+func exportManPages(client *pages.Client, dir string) {
+	platform := client.Platform
+
+	// Find the commands of all platforms
+	client.Platform = pages.AllPlatforms
+	entries, err := client.List()
+
+	if err != nil {
+		fail(err)
+	}
+
+	err = os.MkdirAll(dir, 0777)
+
+	if err != nil {
+		fail(err)
+	}
+
+	// Only export one page per command
+	exported := make(map[string]bool)
+
+	for _, entry := range entries {
+		if exported[entry.Name] {
+			continue
+		}
+
+		exported[entry.Name] = true
+
+		// Look for the page we would show first
+		client.Platform = platform
+		_, page, err := client.Lookup(entry.Name)
+
+		if err != nil {
+			client.Platform = pages.AllPlatforms
+			_, page, err = client.Lookup(entry.Name)
+		}
+
+		if err != nil {
+			fail(err)
+		}
+
+		var man bytes.Buffer
+		pages.PrintMan(&man, page)
+
+		path := filepath.Join(dir, "tldr-"+entry.Name+"."+pages.ManSection)
+		err = ioutil.WriteFile(path, man.Bytes(), 0666)
+
+		if err != nil {
+			fail(err)
+		}
+	}
+
+	client.Platform = platform
+
+	fmt.Fprintf(os.Stderr, "exported %d pages to %s\n", len(exported), dir)
+}
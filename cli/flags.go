@@ -28,7 +28,16 @@ var (
 	language = flag.StringP("language", "L", "", "overide default `lang`uage")
 	search   = flag.StringP("search", "s", "", "list pages matching `regex`")
 	purge    = flag.Bool("purge", false, "remove database from disk")
-	render   = flag.String("render", "", "render page from `file`")
+	imprt    = flag.String("import", "", "build database from local zip or directory at `path`")
+	render   = flag.String("render", "", "render pages from `file` and other files given as arguments, - for stdin")
+	lint     = flag.Bool("lint", false, "check the pages in the files given as arguments")
+	watch    = flag.Bool("watch", false, "render again whenever a file given to --render changes")
+	source   = flag.String("source", "", "download pages from comma separated `urls` or paths")
+	format   = flag.String("format", "", "output `format`: raw, plain, ansi, json, html or man")
+	color    = flag.String("color", "auto", "`when` to use colors: auto, always or never")
+	width    = flag.Int("width", 0, "wrap pages at `columns`, by default the width of the terminal")
+	noPager  = flag.Bool("no-pager", false, "never show output in a pager")
+	export   = flag.String("export-man", "", "write a man page for every command in the database to `dir`")
 	version  = flag.Bool("version", false, "version for tldr")
 
 	// Add hidden scripting flags
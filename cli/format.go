@@ -0,0 +1,177 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/elecprog/tldr/pages"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Supported output formats
+const (
+	formatRaw   = "raw"
+	formatPlain = "plain"
+	formatANSI  = "ansi"
+	formatJSON  = "json"
+	formatHTML  = "html"
+	formatMan   = "man"
+)
+
+// Supported color settings
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// validateFormat checks if the requested output format and color setting are supported
+func validateFormat() error {
+	switch *format {
+	case "", formatRaw, formatPlain, formatANSI, formatJSON, formatHTML, formatMan:
+	default:
+		return errors.New("unsupported format '" + *format + "'")
+	}
+
+	switch *color {
+	case colorAuto, colorAlways, colorNever:
+	default:
+		return errors.New("unsupported color setting '" + *color + "'")
+	}
+
+	if *width < 0 {
+		return errors.New("the width can't be negative")
+	}
+
+	return nil
+}
+
+// useColor checks if colors should be used for output written to the given
+// file, by default only terminals get colors but this can be overridden
+// using the NO_COLOR and CLICOLOR_FORCE environment variables
+func useColor(file *os.File) bool {
+	return colorSetting(terminal.IsTerminal(int(file.Fd())))
+}
+
+// colorSetting checks if colors should be used, fallback is
+// used if neither the user nor the environment has a preference
+func colorSetting(fallback bool) bool {
+	switch *color {
+	case colorAlways:
+		return true
+
+	case colorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	}
+
+	return fallback
+}
+
+// prettyPrintOptions are the options for output written to the given file
+func prettyPrintOptions(file *os.File) pages.PrettyPrintOptions {
+	return pages.PrettyPrintOptions{
+		Color: useColor(file),
+		Theme: getTheme(),
+		Width: getWidth(file),
+	}
+}
+
+// getWidth returns the width at which pages written to the given file are
+// wrapped, by default this is the width of the terminal and zero, meaning
+// no wrapping, if the file isn't a terminal
+func getWidth(file *os.File) int {
+	if *width > 0 {
+		return *width
+	}
+
+	columns, _, err := terminal.GetSize(int(file.Fd()))
+
+	if err != nil {
+		return 0
+	}
+
+	return columns
+}
+
+// pageFormat returns the format in which pages are shown, without a requested
+// format pages are formatted for terminals and when colors are forced, other
+// programs get the raw pages. Whether colors are used is decided separately.
+func pageFormat() string {
+	if *format != "" {
+		return *format
+	}
+
+	if terminal.IsTerminal(int(os.Stdout.Fd())) || colorSetting(false) {
+		return formatANSI
+	}
+
+	return formatRaw
+}
+
+// structuredFormat checks if the requested format is meant for other
+// programs, in which case a missing page is an error
+func structuredFormat() bool {
+	return *format == formatJSON || *format == formatHTML || *format == formatMan
+}
+
+// jsonPage is a page as shown in JSON
+type jsonPage struct {
+	pages.Entry
+	*pages.Page
+}
+
+// newJSONPage parses the page for showing it in JSON
+func newJSONPage(entry pages.Entry, source []byte) jsonPage {
+	page, _ := pages.Parse(source)
+
+	// Pages read from files only have a title
+	if entry.Name == "" {
+		entry.Name = page.Title
+	}
+
+	if page.Description == nil {
+		page.Description = []pages.Line{}
+	}
+
+	if page.Examples == nil {
+		page.Examples = []pages.Example{}
+	}
+
+	return jsonPage{entry, page}
+}
+
+// printJSON prints the value as indented JSON
+func printJSON(value interface{}) {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(value)
+
+	if err != nil {
+		fail(err)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/elecprog/tldr/pages"
+)
+
+func TestJSONPage(t *testing.T) {
+	source := "# tar\n\n" +
+		"> Archiver.\n" +
+		"> More information: <https://example.com>.\n\n" +
+		"- Create `x`:\n\n" +
+		"`tar cf {{target.tar}}`\n"
+
+	want := `{
+  "name": "tar",
+  "platform": "common",
+  "language": "en",
+  "title": "tar",
+  "description": [
+    {
+      "text": "Archiver.",
+      "tokens": [
+        {
+          "type": "text",
+          "text": "Archiver."
+        }
+      ]
+    }
+  ],
+  "more_information": "https://example.com",
+  "examples": [
+    {
+      "description": {
+        "text": "Create x:",
+        "tokens": [
+          {
+            "type": "text",
+            "text": "Create "
+          },
+          {
+            "type": "code",
+            "text": "x"
+          },
+          {
+            "type": "text",
+            "text": ":"
+          }
+        ]
+      },
+      "command": {
+        "text": "tar cf target.tar",
+        "tokens": [
+          {
+            "type": "code",
+            "text": "tar cf "
+          },
+          {
+            "type": "placeholder",
+            "text": "target.tar"
+          }
+        ]
+      }
+    }
+  ]
+}`
+
+	got, err := json.MarshalIndent(newJSONPage(pages.Entry{Name: "tar", Platform: pages.Common, Language: pages.English}, []byte(source)), "", "  ")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestJSONPageFromFile(t *testing.T) {
+	// Pages read from files only have a title, and lists are never null
+	want := `{"name":"ls","title":"ls","description":[],"examples":[]}`
+
+	got, err := json.Marshal(newJSONPage(pages.Entry{}, []byte("# ls\n")))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/elecprog/tldr/pages"
+)
+
+// lintFiles checks the pages in the given files and exits with
+// a non-zero status if any of them has problems
+func lintFiles(paths []string) {
+	failed := false
+
+	for _, path := range paths {
+		page, err := readPageFile(path)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			failed = true
+			continue
+		}
+
+		for _, lintErr := range pages.Lint(page) {
+			fmt.Println(path + ":" + lintErr.Error())
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
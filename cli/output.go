@@ -0,0 +1,230 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/elecprog/tldr/pages"
+)
+
+// fail tells the user what went wrong and exits
+func fail(err error) {
+	// Whatever we have shown so far shouldn't get lost
+	flushPager()
+
+	if errors.Is(err, pages.ErrEmptyDatabase) {
+		pages.EmptyDatabase(os.Stderr, prettyPrintOptions(os.Stderr))
+
+	} else {
+		fmt.Fprintln(os.Stderr, "error:", err)
+	}
+
+	os.Exit(1)
+}
+
+// showPage shows the page for the command given as arguments
+func showPage(client *pages.Client, args []string) {
+	entry, page, err := client.Lookup(args...)
+
+	if errors.Is(err, pages.ErrPageNotFound) && !structuredFormat() {
+		suggestions, _ := client.Suggest(args...)
+		pages.PageUnavailable(stdout, entry.Name, suggestions, prettyPrintOptions(os.Stdout))
+		return
+	}
+
+	if err != nil {
+		fail(err)
+	}
+
+	printPage(entry, page)
+}
+
+// readPageFile reads the page in the given file, - stands for stdin
+func readPageFile(path string) ([]byte, error) {
+	if path == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+
+	return ioutil.ReadFile(path)
+}
+
+// renderFiles shows the pages in the given files and exits with
+// a non-zero status if any of them couldn't be read
+func renderFiles(paths []string) {
+	ok := renderAll(paths)
+	flushPager()
+
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// renderAll shows the pages in the given files, if there are multiple
+// every page is preceded by a heading with its file name unless the format
+// is meant for other programs. It returns false if any of them couldn't be read.
+func renderAll(paths []string) bool {
+	failed := false
+
+	// JSON and HTML output are collected in a single array or document
+	var parsed []jsonPage
+	var sources [][]byte
+
+	for i, path := range paths {
+		page, err := readPageFile(path)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			failed = true
+			continue
+		}
+
+		if *format == formatJSON {
+			parsed = append(parsed, newJSONPage(pages.Entry{}, page))
+			continue
+		}
+
+		if *format == formatHTML {
+			sources = append(sources, page)
+			continue
+		}
+
+		if len(paths) > 1 && !structuredFormat() {
+			if i > 0 {
+				fmt.Fprintln(stdout)
+			}
+
+			if path == "-" {
+				path = "standard input"
+			}
+
+			fmt.Fprintln(stdout, "==> "+path+" <==")
+		}
+
+		printPage(pages.Entry{}, page)
+	}
+
+	if len(paths) == 1 && len(parsed) == 1 {
+		printJSON(parsed[0])
+
+	} else if parsed != nil {
+		printJSON(parsed)
+	}
+
+	if sources != nil {
+		pages.PrintHTMLDocument(stdout, sources...)
+	}
+
+	return !failed
+}
+
+// printPage prints a page in the requested format, by default it
+// is only formatted when written to a terminal
+func printPage(entry pages.Entry, page []byte) {
+	switch pageFormat() {
+	case formatRaw:
+		stdout.Write(page)
+
+	case formatPlain:
+		options := prettyPrintOptions(os.Stdout)
+		options.Color = false
+
+		pages.PrettyPrint(stdout, page, options)
+
+	case formatANSI:
+		// Formatted output gets colors, unless they are disabled
+		options := prettyPrintOptions(os.Stdout)
+		options.Color = colorSetting(true)
+
+		pages.PrettyPrint(stdout, page, options)
+
+	case formatJSON:
+		printJSON(newJSONPage(entry, page))
+
+	case formatHTML:
+		pages.PrintHTMLDocument(stdout, page)
+
+	case formatMan:
+		pages.PrintMan(stdout, page)
+	}
+}
+
+// printEntries prints the names of the pages, one per line
+func printEntries(entries []pages.Entry, err error) {
+	if err != nil {
+		fail(err)
+	}
+
+	if *format == formatJSON {
+		printJSON(nonNil(entries))
+		return
+	}
+
+	for _, entry := range entries {
+		fmt.Fprintln(stdout, entry.Name)
+	}
+}
+
+// printPlatforms prints the platforms, one per line
+func printPlatforms(platforms []string, err error) {
+	entries := make([]pages.Entry, len(platforms))
+
+	for i, platform := range platforms {
+		entries[i].Platform = platform
+	}
+
+	printLines(platforms, entries, err)
+}
+
+// printLanguages prints the languages, one per line
+func printLanguages(languages []string, err error) {
+	entries := make([]pages.Entry, len(languages))
+
+	for i, language := range languages {
+		entries[i].Language = language
+	}
+
+	printLines(languages, entries, err)
+}
+
+// printLines prints the strings, one per line, or
+// the corresponding entries if JSON was requested
+func printLines(lines []string, entries []pages.Entry, err error) {
+	if err != nil {
+		fail(err)
+	}
+
+	if *format == formatJSON {
+		printJSON(entries)
+		return
+	}
+
+	for _, line := range lines {
+		fmt.Fprintln(stdout, line)
+	}
+}
+
+// nonNil makes sure an empty list is shown as [] in JSON rather than null
+func nonNil(entries []pages.Entry) []pages.Entry {
+	if entries == nil {
+		return []pages.Entry{}
+	}
+
+	return entries
+}
@@ -0,0 +1,143 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// defaultPager is used if PAGER isn't set
+const defaultPager = "less -R"
+
+// escapeCode matches the escape codes used for colors, which take up no space
+var escapeCode = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// stdout is where all output meant for stdout goes, this
+// is a buffer while the output might be shown in a pager
+var stdout io.Writer = os.Stdout
+
+// paged is the output waiting to be shown, nil if there is no pager
+var paged *bytes.Buffer
+
+// startPager collects the output from here on, so it can be shown in
+// a pager if it doesn't fit on the screen. This only happens when stdout
+// is a terminal and the pager isn't disabled.
+func startPager() {
+	if *noPager || paged != nil || !terminal.IsTerminal(int(os.Stdout.Fd())) {
+		return
+	}
+
+	paged = &bytes.Buffer{}
+	stdout = paged
+}
+
+// flushPager shows the collected output, in a pager if
+// it is longer than the height of the terminal
+func flushPager() {
+	if paged == nil {
+		return
+	}
+
+	output := paged.Bytes()
+
+	paged = nil
+	stdout = os.Stdout
+
+	width, height, err := terminal.GetSize(int(os.Stdout.Fd()))
+
+	// Only use the pager if we know it doesn't fit
+	if err != nil || width <= 0 || height <= 0 || screenLines(output, width) < height {
+		os.Stdout.Write(output)
+		return
+	}
+
+	if !runPager(output) {
+		os.Stdout.Write(output)
+	}
+}
+
+// runPager shows the output in the pager set in PAGER, it
+// returns false if the pager couldn't be started
+func runPager(output []byte) bool {
+	command := strings.Fields(getSetting("", "PAGER", "pager", defaultPager))
+
+	if len(command) == 0 {
+		return false
+	}
+
+	// Make sure less shows our colors instead of the escape codes, options
+	// on the command line are added to those in the LESS environment variable
+	if filepath.Base(command[0]) == "less" && !showsColors(command[1:]) {
+		command = append(command, "-R")
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(output)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if cmd.Start() != nil {
+		return false
+	}
+
+	// Quitting the pager early isn't a problem
+	cmd.Wait()
+	return true
+}
+
+// screenLines returns the number of lines the output takes up on a
+// terminal of the given width, taking lines which wrap into account
+func screenLines(output []byte, width int) int {
+	lines := 0
+
+	for _, line := range bytes.Split(bytes.TrimSuffix(output, []byte("\n")), []byte("\n")) {
+		columns := utf8.RuneCount(escapeCode.ReplaceAll(line, nil))
+
+		// Even empty lines take up a line
+		if columns == 0 {
+			lines++
+		} else {
+			lines += (columns + width - 1) / width
+		}
+	}
+
+	return lines
+}
+
+// showsColors checks if the arguments to less include -R or -r,
+// which make it show colors
+func showsColors(args []string) bool {
+	for _, arg := range args {
+		switch {
+		case arg == "--RAW-CONTROL-CHARS", arg == "--raw-control-chars":
+			return true
+
+		case strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && strings.ContainsAny(arg, "Rr"):
+			return true
+		}
+	}
+
+	return false
+}
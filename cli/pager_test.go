@@ -0,0 +1,62 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"testing"
+)
+
+func TestScreenLines(t *testing.T) {
+	tests := []struct {
+		output string
+		lines  int
+	}{
+		{"", 1},
+		{"one\n", 1},
+		{"one\n\ntwo\n", 3},
+		{"0123456789\n", 1},
+		{"0123456789a\n", 2},
+		{"\033[91m0123456789\033[0m\n", 1},
+		{"ééééééééééé", 2},
+	}
+
+	for _, test := range tests {
+		if lines := screenLines([]byte(test.output), 10); lines != test.lines {
+			t.Errorf("screenLines(%q) = %d, want %d", test.output, lines, test.lines)
+		}
+	}
+}
+
+func TestShowsColors(t *testing.T) {
+	tests := []struct {
+		args   []string
+		colors bool
+	}{
+		{nil, false},
+		{[]string{"-FX"}, false},
+		{[]string{"-R"}, true},
+		{[]string{"-FRX"}, true},
+		{[]string{"-r"}, true},
+		{[]string{"--RAW-CONTROL-CHARS"}, true},
+		{[]string{"--quit-if-one-screen"}, false},
+	}
+
+	for _, test := range tests {
+		if colors := showsColors(test.args); colors != test.colors {
+			t.Errorf("showsColors(%q) = %v, want %v", test.args, colors, test.colors)
+		}
+	}
+}
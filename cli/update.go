@@ -0,0 +1,196 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/elecprog/tldr/pages"
+)
+
+// defaultMaxAge is the number of days after which the pages are refreshed
+// automatically if the user didn't configure otherwise
+const defaultMaxAge = 30
+
+// autoRetryInterval is how long we wait before trying to refresh
+// the pages automatically again after an attempt failed
+const autoRetryInterval = 24 * time.Hour
+
+// Network settings for automatic refreshes, which shouldn't keep
+// the user waiting for long, unless configured otherwise
+const (
+	autoConnectTimeout = 3 * time.Second
+	autoTimeout        = 30 * time.Second
+)
+
+// getMaxAge returns the number of days after which the pages should be
+// refreshed, zero means they are never refreshed automatically
+func getMaxAge() int {
+	setting := getSetting("", "TLDR_AUTO_UPDATE", "auto-update", "")
+
+	switch setting {
+	case "":
+		return defaultMaxAge
+
+	case "never", "off", "false":
+		return 0
+	}
+
+	days, err := strconv.Atoi(setting)
+
+	if err != nil || days < 0 {
+		fmt.Fprintln(os.Stderr, "warning: invalid auto-update setting '"+setting+"', expected a number of days")
+		return defaultMaxAge
+	}
+
+	return days
+}
+
+// autoUpdate refreshes the database at the given path if it is older than
+// the configured number of days. If that fails the old pages are kept, the
+// user is notified and we don't try again for a while.
+func autoUpdate(dbPath string) {
+	maxAge := getMaxAge()
+
+	if maxAge == 0 {
+		return
+	}
+
+	updated, err := pages.UpdatedAt(dbPath)
+
+	// A database we can't read is rebuilt by checkSchema
+	if err != nil {
+		return
+	}
+
+	// Pages imported from a local archive or checkout only change when the
+	// user imports them again, the configured sources would replace them
+	source, err := pages.UpdatedFrom(dbPath)
+
+	if err != nil || (source != "" && !remoteLocation(source)) {
+		return
+	}
+
+	// Is it still fresh?
+	age := time.Since(updated)
+
+	if age < time.Duration(maxAge)*24*time.Hour {
+		return
+	}
+
+	// Only try again if the last attempt was a while ago
+	checked, err := pages.CheckedAt(dbPath)
+
+	if err == nil && time.Since(checked) >= autoRetryInterval {
+		skipped, err := pages.Update(dbPath, getSources(), getAutoUpdateOptions())
+
+		if err == nil {
+			reportSkipped(skipped)
+			return
+		}
+	}
+
+	// Leave the details to an explicit update
+	if updated.IsZero() {
+		fmt.Fprintln(os.Stderr, "warning: pages are outdated and could not be refreshed, run tldr --update for details")
+
+	} else {
+		days := int(age.Hours() / 24)
+		fmt.Fprintln(os.Stderr, "warning: pages are", days, "days old and could not be refreshed, run tldr --update for details")
+	}
+}
+
+// remoteLocation checks if pages at the location have to be downloaded
+func remoteLocation(location string) bool {
+	u, err := url.Parse(location)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// getAutoUpdateOptions returns the options for automatic refreshes, these
+// don't retry and give up sooner unless the timeouts are configured
+func getAutoUpdateOptions() pages.UpdateOptions {
+	options := getUpdateOptions()
+	options.Retries = -1
+
+	if options.ConnectTimeout == 0 {
+		options.ConnectTimeout = autoConnectTimeout
+	}
+
+	if options.Timeout == 0 {
+		options.Timeout = autoTimeout
+	}
+
+	return options
+}
+
+// reportSkipped tells the user which files were left out of the database
+func reportSkipped(skipped []pages.SkippedFile) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, "warning: skipped", len(skipped), "files:")
+
+	for _, file := range skipped {
+		fmt.Fprintln(os.Stderr, "  "+file.Name+":", file.Reason)
+	}
+}
+
+// checkSchema makes sure the database at the given path has a layout we
+// understand, older databases are upgraded and newer ones are rebuilt
+func checkSchema(dbPath string) {
+	version, err := pages.Migrate(dbPath)
+
+	if err == nil {
+		if version != pages.SchemaVersion {
+			fmt.Fprintln(os.Stderr, "Upgraded the database from format version", version, "to", pages.SchemaVersion)
+		}
+
+		return
+	}
+
+	if _, newer := err.(*pages.SchemaError); newer {
+		fmt.Fprintln(os.Stderr, "warning:", err.Error()+", rebuilding it")
+
+	} else {
+		fmt.Fprintln(os.Stderr, "warning: failed to upgrade the database:", err.Error()+", rebuilding it")
+	}
+
+	skipped, err := pages.Update(dbPath, getSources(), getUpdateOptions())
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+
+	reportSkipped(skipped)
+
+	// Make sure the rebuild actually fixed it
+	version, err = pages.Migrate(dbPath)
+
+	if err == nil && version != pages.SchemaVersion {
+		err = fmt.Errorf("rebuilt database has format version %d", version)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error: failed to rebuild the database:", err)
+		os.Exit(1)
+	}
+}
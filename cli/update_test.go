@@ -0,0 +1,35 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import "testing"
+
+func TestRemoteLocation(t *testing.T) {
+	tests := map[string]bool{
+		"https://tldr.sh/assets/tldr.zip": true,
+		"http://mirror.example.com/tldr":  true,
+		"file:///srv/tldr.zip":            false,
+		"/srv/tldr.zip":                   false,
+		"tldr-main":                       false,
+		`C:\tldr\tldr.zip`:                false,
+	}
+
+	for location, remote := range tests {
+		if remoteLocation(location) != remote {
+			t.Errorf("remoteLocation(%q) = %v", location, !remote)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/elecprog/tldr/pages"
+)
+
+// pollInterval is how often watched files are checked for changes
+const pollInterval = 250 * time.Millisecond
+
+// fileState is what we look at to detect changes to a file
+type fileState struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+// watchFiles renders the pages in the given files every time one of them
+// changes, together with the problems in them. This never returns.
+func watchFiles(paths []string) {
+	for _, path := range paths {
+		if path == "-" {
+			fail(errors.New("can't watch standard input"))
+		}
+	}
+
+	// We poll the files, which works the same on every platform
+	// and is more than fast enough for a handful of files
+	states := make([]fileState, len(paths))
+
+	for {
+		changed := false
+
+		for i, path := range paths {
+			state := fileState{}
+
+			if info, err := os.Stat(path); err == nil {
+				state = fileState{info.ModTime(), info.Size(), true}
+			}
+
+			if state != states[i] {
+				states[i] = state
+				changed = true
+			}
+		}
+
+		if changed {
+			// Clear the screen and render everything again
+			fmt.Print("\033[H\033[2J")
+			renderAll(paths)
+			showProblems(paths)
+		}
+
+		time.Sleep(pollInterval)
+	}
+}
+
+// showProblems lists the problems in the pages in the given files
+func showProblems(paths []string) {
+	for _, path := range paths {
+		page, err := readPageFile(path)
+
+		if err != nil {
+			// renderAll already complained
+			continue
+		}
+
+		for _, lintErr := range pages.Lint(page) {
+			fmt.Println(path + ":" + lintErr.Error())
+		}
+	}
+}
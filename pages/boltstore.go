@@ -0,0 +1,187 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"bytes"
+	"sort"
+
+	"go.etcd.io/bbolt"
+)
+
+// BoltStore is a PageStore backed by a bbolt database as built by Update
+type BoltStore struct {
+	db *bbolt.DB
+}
+
+// NewBoltStore returns a PageStore for the given database
+func NewBoltStore(db *bbolt.DB) *BoltStore {
+	return &BoltStore{db: db}
+}
+
+// languageBucket returns the bucket containing the pages of a language,
+// note this may be nil
+func languageBucket(tx *bbolt.Tx, language string) *bbolt.Bucket {
+	// English pages are stored in the default bucket
+	if language == English {
+		return tx.Bucket(defaultBucket)
+	}
+
+	// The default and metadata buckets are no languages
+	if language == string(defaultBucket) || language == string(metaBucket) {
+		return nil
+	}
+
+	return tx.Bucket([]byte(language))
+}
+
+// platformBucket returns the bucket containing the pages of a language
+// for a platform, note this may be nil
+func platformBucket(tx *bbolt.Tx, language, platform string) *bbolt.Bucket {
+	lang := languageBucket(tx, language)
+
+	if lang == nil {
+		return nil
+	}
+
+	return lang.Bucket([]byte(platform))
+}
+
+// Page implements PageStore
+func (store *BoltStore) Page(language, platform, name string) ([]byte, error) {
+	var page []byte
+
+	err := store.db.View(
+		func(tx *bbolt.Tx) error {
+			bucket := platformBucket(tx, language, platform)
+
+			if bucket == nil {
+				return nil
+			}
+
+			stored := bucket.Get([]byte(name))
+
+			if stored == nil {
+				return nil
+			}
+
+			// Pages might be compressed
+			decoded, err := decodePage(readMetadata(tx)[metaCodec], stored)
+
+			if err != nil {
+				return err
+			}
+
+			// The data in the database is only valid during the transaction
+			page = append([]byte{}, decoded...)
+			return nil
+		})
+
+	return page, err
+}
+
+// Names implements PageStore
+func (store *BoltStore) Names(language, platform string) ([]string, error) {
+	var names []string
+
+	err := store.db.View(
+		func(tx *bbolt.Tx) error {
+			bucket := platformBucket(tx, language, platform)
+
+			if bucket == nil {
+				return nil
+			}
+
+			// Keys are byte ordered, so they are also in alphabetical order
+			return bucket.ForEach(
+				func(name, _ []byte) error {
+					names = append(names, string(name))
+					return nil
+				})
+		})
+
+	return names, err
+}
+
+// Platforms implements PageStore
+func (store *BoltStore) Platforms() ([]string, error) {
+	var platforms []string
+
+	err := store.db.View(
+		func(tx *bbolt.Tx) error {
+			// We assume that all pages appear in english
+			root := languageBucket(tx, English)
+
+			if root == nil {
+				return nil
+			}
+
+			// The platforms are buckets in the root
+			return root.ForEach(
+				func(name, value []byte) error {
+					if value == nil && !bytes.Equal(name, commonBucket) {
+						platforms = append(platforms, string(name))
+					}
+
+					return nil
+				})
+		})
+
+	return platforms, err
+}
+
+// Languages implements PageStore
+func (store *BoltStore) Languages() ([]string, error) {
+	var languages []string
+
+	err := store.db.View(
+		func(tx *bbolt.Tx) error {
+			// Not even the default bucket -> empty database
+			if tx.Bucket(defaultBucket) == nil {
+				return nil
+			}
+
+			languages = append(languages, English)
+
+			// The other languages are the other top level buckets
+			return tx.ForEach(
+				func(name []byte, _ *bbolt.Bucket) error {
+					if !bytes.Equal(name, defaultBucket) && !bytes.Equal(name, metaBucket) && string(name) != English {
+						languages = append(languages, string(name))
+					}
+
+					return nil
+				})
+		})
+
+	// Keep them in order
+	sort.Strings(languages)
+
+	return languages, err
+}
+
+// Metadata implements PageStore
+func (store *BoltStore) Metadata(key string) (string, error) {
+	var value string
+
+	err := store.db.View(
+		func(tx *bbolt.Tx) error {
+			value = readMetadata(tx)[key]
+			return nil
+		})
+
+	return value, err
+}
@@ -0,0 +1,321 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/elecprog/tldr/targets"
+	"go.etcd.io/bbolt"
+)
+
+// Errors returned by a Client, these are wrapped to add details
+// so they should be checked using errors.Is
+var (
+	// ErrPageNotFound is returned if there is no page for a command
+	ErrPageNotFound = errors.New("page not found")
+
+	// ErrEmptyDatabase is returned if there are no pages at all
+	ErrEmptyDatabase = errors.New("the database is empty")
+
+	// ErrUnsupportedPlatform is returned if there are no pages for the platform
+	ErrUnsupportedPlatform = errors.New("unsupported platform")
+)
+
+// AllPlatforms can be used as the platform of a Client to list
+// and search the pages of all platforms
+const AllPlatforms = "all"
+
+// Entry describes a page in a store
+type Entry struct {
+	// Name is the command the page is about
+	Name string `json:"name,omitempty"`
+
+	// Platform is the platform the page applies to
+	Platform string `json:"platform,omitempty"`
+
+	// Language is the language the page is written in
+	Language string `json:"language,omitempty"`
+}
+
+// Client gives access to the pages in a store
+type Client struct {
+	// Platform is the platform for which pages are looked up,
+	// this defaults to the current platform
+	Platform string
+
+	// Language is the preferred language of the pages, english is
+	// used if no translation exists, this defaults to the users language
+	Language string
+
+	// store is nil if there is no database yet
+	store PageStore
+
+	// dbPath and db are only set if the store is a database on disk
+	dbPath string
+	db     *bbolt.DB
+}
+
+// NewClient returns a Client for the pages in the given store
+func NewClient(store PageStore) *Client {
+	return &Client{
+		Platform: targets.OsDir,
+		Language: targets.CurrentLanguage,
+		store:    store,
+	}
+}
+
+// Open returns a Client for the database at the given path, if there is no
+// database yet the client stays empty until Update or Import is called
+func Open(dbPath string) (*Client, error) {
+	client := NewClient(nil)
+	client.dbPath = dbPath
+
+	return client, client.open()
+}
+
+// open opens the database of the client if it exists
+func (client *Client) open() error {
+	if _, err := os.Stat(client.dbPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	// We open the databse with a timeout of one second to not keep
+	// on attempting if there is something wrong. The database is only
+	// changed by replacing it, so we can always open it as read only.
+	db, err := bbolt.Open(client.dbPath, 0600,
+		&bbolt.Options{
+			Timeout:  1 * time.Second,
+			ReadOnly: true,
+		})
+
+	if err != nil {
+		return err
+	}
+
+	client.db = db
+	client.store = NewBoltStore(db)
+
+	return nil
+}
+
+// Close releases the database of the client, if any
+func (client *Client) Close() error {
+	if client.db == nil {
+		return nil
+	}
+
+	err := client.db.Close()
+	client.db = nil
+	client.store = nil
+
+	return err
+}
+
+// Update replaces the database of the client with the pages from the first
+// of the given sources that works, see the Update function
+func (client *Client) Update(locations []string, options UpdateOptions) ([]SkippedFile, error) {
+	return client.rebuild(func() ([]SkippedFile, error) {
+		return Update(client.dbPath, locations, options)
+	})
+}
+
+// Import replaces the database of the client with the pages from a local
+// zip archive or directory, see the Import function
+func (client *Client) Import(path string, options UpdateOptions) ([]SkippedFile, error) {
+	return client.rebuild(func() ([]SkippedFile, error) {
+		return Import(client.dbPath, path, options)
+	})
+}
+
+// rebuild closes the database while it is rebuilt and then reopens it
+func (client *Client) rebuild(build func() ([]SkippedFile, error)) ([]SkippedFile, error) {
+	if client.dbPath == "" {
+		return nil, errors.New("only clients for a database can be updated")
+	}
+
+	err := client.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	skipped, err := build()
+
+	// Whatever happened, we still want a database
+	if openErr := client.open(); err == nil {
+		err = openErr
+	}
+
+	return skipped, err
+}
+
+// Store returns the store of the client, this is nil if it is empty
+func (client *Client) Store() PageStore {
+	return client.store
+}
+
+// checkEmpty makes sure the store contains pages
+func (client *Client) checkEmpty() error {
+	if client.store == nil {
+		return ErrEmptyDatabase
+	}
+
+	empty, err := isEmpty(client.store)
+
+	if err == nil && empty {
+		err = ErrEmptyDatabase
+	}
+
+	return err
+}
+
+// check makes sure the store contains pages for the platform of the client
+func (client *Client) check() error {
+	err := client.checkEmpty()
+
+	if err != nil {
+		return err
+	}
+
+	if client.Platform == AllPlatforms {
+		return nil
+	}
+
+	supported, err := hasPlatform(client.store, client.Platform)
+
+	if err != nil {
+		return err
+	}
+
+	if !supported {
+		return fmt.Errorf("%w '%s'", ErrUnsupportedPlatform, client.Platform)
+	}
+
+	return nil
+}
+
+// Lookup returns the most relevant page for a command, the arguments are the
+// command followed by its subcommands. Translations are preferred over
+// english pages and platform specific pages over common ones.
+func (client *Client) Lookup(command ...string) (Entry, []byte, error) {
+	// Join the subcommands with a `-`
+	entry := Entry{Name: strings.Join(command, "-")}
+
+	err := client.check()
+
+	if err != nil {
+		return entry, nil, err
+	}
+
+	for _, entry.Language = range []string{client.Language, English} {
+		for _, entry.Platform = range client.platforms() {
+			page, err := client.store.Page(entry.Language, entry.Platform, entry.Name)
+
+			if page != nil || err != nil {
+				return entry, page, err
+			}
+		}
+	}
+
+	return Entry{Name: entry.Name}, nil, fmt.Errorf("%w: %s", ErrPageNotFound, entry.Name)
+}
+
+// platforms returns the platforms for which the client should look for
+// pages, in order of relevance
+func (client *Client) platforms() []string {
+	switch client.Platform {
+	case Common:
+		return []string{Common}
+
+	case AllPlatforms:
+		platforms, _ := client.store.Platforms()
+		return append([]string{Common}, platforms...)
+
+	default:
+		return []string{client.Platform, Common}
+	}
+}
+
+// List returns all pages for the platform of the client, only english
+// pages are listed as other languages only contain translations
+func (client *Client) List() ([]Entry, error) {
+	return client.Search("")
+}
+
+// Search returns all pages for the platform of the client with
+// a name matching the given POSIX regular expression
+func (client *Client) Search(regex string) ([]Entry, error) {
+	err := client.check()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a matcher from the regex
+	matcher, err := regexp.CompilePOSIX(regex)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO strip doubles
+	var entries []Entry
+
+	for _, platform := range client.platforms() {
+		// Names are in alphabetical order
+		names, err := client.store.Names(English, platform)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			if matcher.MatchString(name) {
+				entries = append(entries, Entry{name, platform, English})
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// Platforms returns all platforms apart from common
+func (client *Client) Platforms() ([]string, error) {
+	err := client.checkEmpty()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.store.Platforms()
+}
+
+// Languages returns all languages
+func (client *Client) Languages() ([]string, error) {
+	err := client.checkEmpty()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.store.Languages()
+}
@@ -0,0 +1,188 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newTestClient returns a client for linux with french as preferred
+// language, backed by a MemoryStore containing a few pages
+func newTestClient() *Client {
+	store := NewMemoryStore()
+	store.Add(English, Common, "tar", []byte("# tar"))
+	store.Add(English, Common, "ls", []byte("# ls common"))
+	store.Add(English, "linux", "ls", []byte("# ls linux"))
+	store.Add(English, "linux", "apt", []byte("# apt"))
+	store.Add(English, "osx", "open", []byte("# open"))
+	store.Add("fr", Common, "tar", []byte("# tar fr"))
+
+	client := NewClient(store)
+	client.Platform = "linux"
+	client.Language = "fr"
+
+	return client
+}
+
+func TestLookup(t *testing.T) {
+	client := newTestClient()
+
+	tests := []struct {
+		command []string
+		entry   Entry
+		page    string
+	}{
+		{[]string{"tar"}, Entry{"tar", Common, "fr"}, "# tar fr"},
+		{[]string{"ls"}, Entry{"ls", "linux", English}, "# ls linux"},
+		{[]string{"apt"}, Entry{"apt", "linux", English}, "# apt"},
+	}
+
+	for _, test := range tests {
+		entry, page, err := client.Lookup(test.command...)
+
+		if err != nil || entry != test.entry || string(page) != test.page {
+			t.Errorf("Lookup(%q) = %v, %q, %v", test.command, entry, page, err)
+		}
+	}
+
+	// Pages for other platforms aren't shown
+	if _, _, err := client.Lookup("open"); !errors.Is(err, ErrPageNotFound) {
+		t.Errorf("got %v for a page of another platform, want ErrPageNotFound", err)
+	}
+
+	client.Platform = "windows"
+
+	if _, _, err := client.Lookup("tar"); !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Errorf("got %v for an unknown platform, want ErrUnsupportedPlatform", err)
+	}
+}
+
+func TestEmptyStore(t *testing.T) {
+	// Translations alone don't count
+	store := NewMemoryStore()
+	store.Add("fr", Common, "tar", []byte("# tar fr"))
+
+	client := NewClient(store)
+
+	if _, _, err := client.Lookup("tar"); !errors.Is(err, ErrEmptyDatabase) {
+		t.Errorf("got %v, want ErrEmptyDatabase", err)
+	}
+
+	if languages, _ := store.Languages(); len(languages) != 0 {
+		t.Errorf("got languages %v for a store without english pages", languages)
+	}
+}
+
+func TestListAndSearch(t *testing.T) {
+	client := newTestClient()
+
+	entries, err := client.List()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Entry{
+		{"apt", "linux", English},
+		{"ls", "linux", English},
+		{"ls", Common, English},
+		{"tar", Common, English},
+	}
+
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("List() = %v, want %v", entries, want)
+	}
+
+	client.Platform = AllPlatforms
+	entries, err = client.Search("^(o|t)")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want = []Entry{
+		{"tar", Common, English},
+		{"open", "osx", English},
+	}
+
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Search() = %v, want %v", entries, want)
+	}
+}
+
+func TestPlatformsAndLanguages(t *testing.T) {
+	client := newTestClient()
+
+	platforms, err := client.Platforms()
+
+	if err != nil || !reflect.DeepEqual(platforms, []string{"linux", "osx"}) {
+		t.Errorf("Platforms() = %v, %v", platforms, err)
+	}
+
+	languages, err := client.Languages()
+
+	if err != nil || !reflect.DeepEqual(languages, []string{English, "fr"}) {
+		t.Errorf("Languages() = %v, %v", languages, err)
+	}
+}
+
+func TestStoresAgree(t *testing.T) {
+	files := map[string]string{
+		"pages/common/tar.md":   "# tar",
+		"pages/linux/apt.md":    "# apt",
+		"pages/osx/open.md":     "# open",
+		"pages.fr/common/ls.md": "# ls fr",
+	}
+
+	memory := NewMemoryStore()
+
+	for name, page := range files {
+		language, platform, command, _ := locatePage(name)
+		memory.Add(language, platform, command, []byte(page))
+	}
+
+	dbPath := filepath.Join(tempDir(t), "tldr.bbolt")
+
+	if _, err := Import(dbPath, writeZip(t, files), UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := Open(dbPath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	for _, store := range []PageStore{memory, client.Store()} {
+		platforms, _ := store.Platforms()
+		languages, _ := store.Languages()
+		names, _ := store.Names(English, Common)
+		page, _ := store.Page("fr", Common, "ls")
+
+		if !reflect.DeepEqual(platforms, []string{"linux", "osx"}) ||
+			!reflect.DeepEqual(languages, []string{English, "fr"}) ||
+			!reflect.DeepEqual(names, []string{"tar"}) ||
+			string(page) != "# ls fr" {
+			t.Errorf("%T has platforms %v, languages %v, common pages %v and page %q",
+				store, platforms, languages, names, page)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io/ioutil"
+)
+
+// Codecs in which pages can be stored, the codec used by a database is
+// stored in its metadata, databases without one store the pages as is
+const (
+	// codecRaw stores the pages uncompressed
+	codecRaw = ""
+
+	// codecDeflate compresses every page separately using DEFLATE
+	// with pageDictionary as preset dictionary
+	codecDeflate = "deflate-1"
+)
+
+// pageDictionary contains text which is common in pages, this greatly
+// improves the compression of small pages. Changing it requires a new codec.
+var pageDictionary = []byte(" file directory path/to/ --version --help" +
+	" a the of and to for with from in on using specific given current" +
+	" Display List Create Show Print Start Run Remove Set Update Install" +
+	"\n\n- Display help:\n\n`{{command}} --help`" +
+	"\n\n- Display version:\n\n`{{command}} --version`" +
+	"\n\n- \n\n`{{path/to/file}}`\n\n- \n\n`{{path/to/directory}}`" +
+	"\n> More information: <https://>.\n\n- ")
+
+// encodePage compresses a page for storage using the current codec
+func encodePage(page []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writer, err := flate.NewWriterDict(&buf, flate.BestCompression, pageDictionary)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = writer.Write(page)
+
+	if err == nil {
+		err = writer.Close()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodePage returns the contents of a page stored using the given codec
+func decodePage(codec string, stored []byte) ([]byte, error) {
+	switch codec {
+	case codecRaw:
+		return stored, nil
+
+	case codecDeflate:
+		reader := flate.NewReaderDict(bytes.NewReader(stored), pageDictionary)
+		defer reader.Close()
+
+		return ioutil.ReadAll(reader)
+
+	default:
+		return nil, errors.New("unsupported page encoding '" + codec + "', try updating the database using tldr --update")
+	}
+}
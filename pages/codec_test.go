@@ -0,0 +1,62 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	pages := [][]byte{
+		{},
+		[]byte("# ls\n\n> List directory contents.\n\n- List files:\n\n`ls {{path/to/directory}}`\n"),
+		bytes.Repeat([]byte("{{command}} --help\n"), 1000),
+	}
+
+	for _, page := range pages {
+		encoded, err := encodePage(page)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded, err := decodePage(codecDeflate, encoded)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(decoded, page) {
+			t.Errorf("decodePage(encodePage(%.20q...)) = %.20q...", page, decoded)
+		}
+	}
+}
+
+func TestDecodeRaw(t *testing.T) {
+	page := []byte("# ls\n")
+	decoded, err := decodePage(codecRaw, page)
+
+	if err != nil || !bytes.Equal(decoded, page) {
+		t.Errorf("decodePage(raw) = %q, %v, want %q", decoded, err, page)
+	}
+}
+
+func TestDecodeUnknownCodec(t *testing.T) {
+	if _, err := decodePage("brotli-1", []byte("# ls\n")); err == nil {
+		t.Error("decodePage with an unknown codec succeeded")
+	}
+}
@@ -18,72 +18,156 @@ package pages
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
-type color uint16
+// colorKind tells how the value of a Color should be interpreted
+type colorKind uint8
 
 const (
-	colBlack color = iota + 30
-	colRed
-	colGreen
-	colYellow
-	colBlue
-	colMagenta
-	colCyan
-	colGray
-
-	colBrightBlack color = (iota - 8) + 90
-	colBrightRed
-	colBrightGreen
-	colBrightYellow
-	colBrightBlue
-	colBrightMagenta
-	colBrightCyan
-	colBrightGray
-
-	colDefault color = 39
-	colReset   color = 0
-
-	modBold   color = 2 << 8
-	modFaint  color = 2 << 9
-	modItalic color = 2 << 10
-
-	colMask uint64 = 2<<8 - 1
+	// kindDefault is the default color of the terminal
+	kindDefault colorKind = iota
+
+	// kindBasic is one of the 16 standard colors
+	kindBasic
+
+	// kind256 is one of the 256 extended colors
+	kind256
+
+	// kindRGB is a 24-bit truecolor
+	kindRGB
 )
 
-type coloredText struct {
-	value interface{}
-	color color
+// Color is a color in a terminal, the zero value is the default color
+type Color struct {
+	kind  colorKind
+	value uint32
 }
 
-func colorize(val interface{}, col color) coloredText {
-	return coloredText{value: val, color: col}
+// The 16 standard colors
+var (
+	colBlack         = Color{kindBasic, 0}
+	colRed           = Color{kindBasic, 1}
+	colGreen         = Color{kindBasic, 2}
+	colYellow        = Color{kindBasic, 3}
+	colBlue          = Color{kindBasic, 4}
+	colMagenta       = Color{kindBasic, 5}
+	colCyan          = Color{kindBasic, 6}
+	colGray          = Color{kindBasic, 7}
+	colBrightBlack   = Color{kindBasic, 8}
+	colBrightRed     = Color{kindBasic, 9}
+	colBrightGreen   = Color{kindBasic, 10}
+	colBrightYellow  = Color{kindBasic, 11}
+	colBrightBlue    = Color{kindBasic, 12}
+	colBrightMagenta = Color{kindBasic, 13}
+	colBrightCyan    = Color{kindBasic, 14}
+	colBrightGray    = Color{kindBasic, 15}
+)
+
+// colorNames are the names of the standard colors used in themes
+var colorNames = map[string]Color{
+	"default":        {},
+	"black":          colBlack,
+	"red":            colRed,
+	"green":          colGreen,
+	"yellow":         colYellow,
+	"blue":           colBlue,
+	"magenta":        colMagenta,
+	"cyan":           colCyan,
+	"gray":           colGray,
+	"white":          colGray,
+	"bright-black":   colBrightBlack,
+	"bright-red":     colBrightRed,
+	"bright-green":   colBrightGreen,
+	"bright-yellow":  colBrightYellow,
+	"bright-blue":    colBrightBlue,
+	"bright-magenta": colBrightMagenta,
+	"bright-cyan":    colBrightCyan,
+	"bright-gray":    colBrightGray,
+	"bright-white":   colBrightGray,
 }
 
-func toEscapeCode(col color) string {
-	res := "\033["
+// Style is the way text is shown in a terminal, the
+// zero value shows text as the terminal normally does
+type Style struct {
+	Foreground Color
+	Background Color
+
+	Bold      bool
+	Faint     bool
+	Italic    bool
+	Underline bool
+	Inverse   bool
+}
 
-	res += strconv.FormatUint(uint64(col)&colMask, 10)
+// parameters returns the SGR parameters of the color, base is
+// 30 for the foreground and 40 for the background
+func (col Color) parameters(base int) []string {
+	switch col.kind {
+	case kindBasic:
+		if col.value >= 8 {
+			return []string{strconv.Itoa(base + 60 + int(col.value) - 8)}
+		}
+
+		return []string{strconv.Itoa(base + int(col.value))}
+
+	case kind256:
+		return []string{strconv.Itoa(base + 8), "5", strconv.Itoa(int(col.value))}
+
+	case kindRGB:
+		return []string{
+			strconv.Itoa(base + 8), "2",
+			strconv.Itoa(int(col.value >> 16 & 0xff)),
+			strconv.Itoa(int(col.value >> 8 & 0xff)),
+			strconv.Itoa(int(col.value & 0xff)),
+		}
+	}
 
-	if col&modBold > 0 {
-		res += ";1"
+	return nil
+}
+
+// escapeCode returns the escape code to switch to the style,
+// this is empty if the style doesn't change anything
+func (style Style) escapeCode() string {
+	params := append(style.Foreground.parameters(30), style.Background.parameters(40)...)
+
+	modifiers := []struct {
+		set   bool
+		param string
+	}{
+		{style.Bold, "1"},
+		{style.Faint, "2"},
+		{style.Italic, "3"},
+		{style.Underline, "4"},
+		{style.Inverse, "7"},
 	}
 
-	if col&modFaint > 0 {
-		res += ";2"
+	for _, mod := range modifiers {
+		if mod.set {
+			params = append(params, mod.param)
+		}
 	}
 
-	if col&modItalic > 0 {
-		res += ";3"
+	if len(params) == 0 {
+		return ""
 	}
 
-	return res + "m"
+	return "\033[" + strings.Join(params, ";") + "m"
+}
+
+type coloredText struct {
+	value interface{}
+	style Style
+}
+
+func colorize(val interface{}, style Style) coloredText {
+	return coloredText{value: val, style: style}
 }
 
 func (ct coloredText) Format(f fmt.State, c rune) {
-	if ct.color == colDefault {
+	if code := ct.style.escapeCode(); code == "" {
 		fmt.Fprint(f, ct.value)
 	} else {
-		fmt.Fprint(f, toEscapeCode(ct.color), ct.value, "\033[0m")
+		fmt.Fprint(f, code, ct.value, "\033[0m")
 	}
 }
@@ -0,0 +1,44 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import "testing"
+
+func TestEscapeCode(t *testing.T) {
+	tests := []struct {
+		style Style
+		code  string
+	}{
+		{Style{}, ""},
+		{Style{Foreground: colRed}, "\033[31m"},
+		{Style{Foreground: colBrightRed}, "\033[91m"},
+		{Style{Background: colBlue}, "\033[44m"},
+		{Style{Background: colBrightCyan}, "\033[106m"},
+		{Style{Foreground: Color{kind256, 208}}, "\033[38;5;208m"},
+		{Style{Background: Color{kind256, 0}}, "\033[48;5;0m"},
+		{Style{Foreground: Color{kindRGB, 0x87afff}}, "\033[38;2;135;175;255m"},
+		{Style{Background: Color{kindRGB, 0x010203}}, "\033[48;2;1;2;3m"},
+		{Style{Bold: true, Faint: true, Italic: true, Underline: true, Inverse: true}, "\033[1;2;3;4;7m"},
+		{Style{Foreground: Color{kind256, 208}, Background: Color{kindRGB, 0xffffff}, Bold: true},
+			"\033[38;5;208;48;2;255;255;255;1m"},
+	}
+
+	for _, test := range tests {
+		if code := test.style.escapeCode(); code != test.code {
+			t.Errorf("%+v.escapeCode() = %q, want %q", test.style, code, test.code)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"path"
+	"time"
+)
+
+// Defaults for the network settings in UpdateOptions
+const (
+	defaultConnectTimeout = 10 * time.Second
+	defaultTimeout        = 5 * time.Minute
+	defaultRetries        = 3
+)
+
+// firstRetryDelay is how long we wait before the first retry,
+// this doubles for every following attempt
+const firstRetryDelay = time.Second
+
+// newClient returns a HTTP client configured according to the options,
+// proxies are taken from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// environment variables
+func newClient(options UpdateOptions) *http.Client {
+	return &http.Client{
+		Timeout: options.Timeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   options.ConnectTimeout,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			TLSHandshakeTimeout:   options.ConnectTimeout,
+			ResponseHeaderTimeout: options.ConnectTimeout,
+		},
+	}
+}
+
+// download performs the request and reads at most max bytes of the response,
+// retrying with exponential backoff if that fails in a way which might
+// be temporary. The body of the response is already closed when returned.
+func download(req *http.Request, max int64, options UpdateOptions) (*http.Response, []byte, error) {
+	client := newClient(options)
+	delay := firstRetryDelay
+
+	for attempt := 0; ; attempt++ {
+		resp, body, retry, err := downloadOnce(client, req, max, options)
+
+		if err == nil || !retry || attempt >= options.Retries {
+			return resp, body, err
+		}
+
+		if options.Progress != nil {
+			fmt.Fprintf(options.Progress, "warning: %v, retrying in %v\n", err, delay)
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// downloadOnce performs a single attempt of download, if it fails retry
+// tells whether trying again could help
+func downloadOnce(client *http.Client, req *http.Request, max int64, options UpdateOptions) (resp *http.Response, body []byte, retry bool, err error) {
+	resp, err = client.Do(req)
+
+	if err != nil {
+		return nil, nil, temporary(err), err
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		// Continue below
+
+	case resp.StatusCode == http.StatusNotModified:
+		return resp, nil, false, nil
+
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return nil, nil, true, fmt.Errorf("failed to download %s: %s", req.URL, resp.Status)
+
+	default:
+		return nil, nil, false, fmt.Errorf("failed to download %s: %s", req.URL, resp.Status)
+	}
+
+	// Don't even start if we know it's too large
+	if resp.ContentLength > max {
+		return nil, nil, false, fmt.Errorf("%s: larger than %d bytes", req.URL, max)
+	}
+
+	var reader io.Reader = resp.Body
+
+	if options.Progress != nil {
+		reader = &progressReader{
+			reader: reader,
+			out:    options.Progress,
+			name:   path.Base(req.URL.Path),
+			total:  resp.ContentLength,
+		}
+	}
+
+	body, err = ioutil.ReadAll(io.LimitReader(reader, max+1))
+
+	if err != nil {
+		return nil, nil, true, err
+	}
+
+	if int64(len(body)) > max {
+		return nil, nil, false, fmt.Errorf("%s: larger than %d bytes", req.URL, max)
+	}
+
+	return resp, body, false, nil
+}
+
+// temporary checks if an error while connecting might go away by trying
+// again, a refused connection, unknown host or invalid certificate won't
+func temporary(err error) bool {
+	var netErr net.Error
+
+	if !errors.As(err, &netErr) {
+		return false
+	}
+
+	return netErr.Timeout() || netErr.Temporary()
+}
+
+// progressReader shows how much of a download has been read
+type progressReader struct {
+	reader io.Reader
+	out    io.Writer
+	name   string
+
+	// total is the expected size, or -1 if unknown
+	total int64
+	read  int64
+	shown time.Time
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.reader.Read(buf)
+	p.read += int64(n)
+
+	if err != nil {
+		// Clear the line once we are done
+		fmt.Fprint(p.out, "\r\033[K")
+
+	} else if time.Since(p.shown) > 100*time.Millisecond {
+		// Don't redraw too often
+		p.shown = time.Now()
+
+		if p.total > 0 {
+			fmt.Fprintf(p.out, "\r\033[KDownloading %s: %s of %s (%d%%)",
+				p.name, formatSize(p.read), formatSize(p.total), 100*p.read/p.total)
+
+		} else {
+			fmt.Fprintf(p.out, "\r\033[KDownloading %s: %s", p.name, formatSize(p.read))
+		}
+	}
+
+	return n, err
+}
+
+// formatSize formats a number of bytes for humans
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(size)/(1<<20))
+
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(size)/(1<<10))
+
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDownloadDoesNotRetryRefusedConnections(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, _, err = download(req, 1024, UpdateOptions{Retries: 3}.withDefaults())
+
+	if err == nil {
+		t.Fatal("download from a closed server succeeded")
+	}
+
+	if elapsed := time.Since(start); elapsed >= firstRetryDelay {
+		t.Errorf("took %v, the download was retried", elapsed)
+	}
+}
+
+func TestDownloadRetriesServerErrors(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+
+			if requests == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			w.Write([]byte("page"))
+		}))
+
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, body, err := download(req, 1024, UpdateOptions{Retries: 1}.withDefaults())
+
+	if err != nil || string(body) != "page" || requests != 2 {
+		t.Errorf("got %q, %v after %d requests", body, err, requests)
+	}
+}
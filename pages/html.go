@@ -0,0 +1,171 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// htmlStyle is the style sheet included in HTML documents, all rules
+// are scoped to the pages so it can be reused when embedding fragments
+const htmlStyle = `.tldr-page { max-width: 50em; font-family: sans-serif; line-height: 1.4; }
+.tldr-page h1 { font-size: 1.6em; margin-bottom: 0.2em; }
+.tldr-page .tldr-description { margin: 0.2em 0; color: #444; }
+.tldr-page .tldr-examples { list-style: none; padding: 0; }
+.tldr-page .tldr-example { margin: 1em 0; }
+.tldr-page .tldr-example p { margin: 0 0 0.3em; }
+.tldr-page code { font-family: monospace; color: #b22; }
+.tldr-page pre { margin: 0; padding: 0.5em 0.8em; background: #f4f4f4; border-radius: 4px; overflow-x: auto; }
+.tldr-page var { font-style: italic; color: #555; }
+`
+
+// PrintHTMLDocument writes a complete, styled HTML document containing the
+// given pages, the title of the document is the title of the first page
+func PrintHTMLDocument(out io.Writer, sources ...[]byte) {
+	title := "tldr"
+
+	if len(sources) > 0 {
+		if first, _ := Parse(sources[0]); first.Title != "" {
+			title = first.Title + " - tldr"
+		}
+	}
+
+	fmt.Fprint(out, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprint(out, "<title>", html.EscapeString(title), "</title>\n")
+	fmt.Fprint(out, "<style>\n", htmlStyle, "</style>\n</head>\n<body>\n")
+
+	for _, source := range sources {
+		PrintHTML(out, source)
+	}
+
+	fmt.Fprint(out, "</body>\n</html>\n")
+}
+
+// PrintHTML writes the page as an HTML fragment, it uses the style sheet
+// of PrintHTMLDocument when embedded in other documents
+func PrintHTML(out io.Writer, source []byte) {
+	page, _ := Parse(source)
+
+	fmt.Fprint(out, "<article class=\"tldr-page\">\n")
+
+	if page.Title != "" {
+		fmt.Fprint(out, "<h1>", html.EscapeString(page.Title), "</h1>\n")
+	}
+
+	for _, line := range page.Description {
+		fmt.Fprint(out, "<p class=\"tldr-description\">")
+		printHTMLTokens(out, line.Tokens)
+		fmt.Fprint(out, "</p>\n")
+	}
+
+	if page.MoreInfo != "" {
+		fmt.Fprint(out, "<p class=\"tldr-description tldr-more-info\">", moreInfoPrefix, " ")
+		printHTMLLink(out, page.MoreInfo)
+		fmt.Fprint(out, ".</p>\n")
+	}
+
+	if len(page.Examples) > 0 {
+		fmt.Fprint(out, "<ul class=\"tldr-examples\">\n")
+
+		for _, ex := range page.Examples {
+			before, after := ex.splitNotes()
+
+			fmt.Fprint(out, "<li class=\"tldr-example\">\n<p>")
+			printHTMLTokens(out, ex.Description.Tokens)
+			fmt.Fprint(out, "</p>\n")
+			printHTMLNotes(out, before)
+			fmt.Fprint(out, "<pre><code>")
+			printHTMLTokens(out, ex.Command.Tokens)
+			fmt.Fprint(out, "</code></pre>\n")
+			printHTMLNotes(out, after)
+			fmt.Fprint(out, "</li>\n")
+		}
+
+		fmt.Fprint(out, "</ul>\n")
+	}
+
+	fmt.Fprint(out, "</article>\n")
+}
+
+// printHTMLNotes writes the notes of an example
+func printHTMLNotes(out io.Writer, notes []Line) {
+	for _, note := range notes {
+		fmt.Fprint(out, "<p class=\"tldr-note\">")
+		printHTMLTokens(out, note.Tokens)
+		fmt.Fprint(out, "</p>\n")
+	}
+}
+
+// printHTMLTokens writes the tokens with the markup for their kind, code
+// directly following other code is kept in a single element
+func printHTMLTokens(out io.Writer, tokens []Token) {
+	inCode := false
+
+	for i, token := range tokens {
+		code := token.Kind != TextToken
+
+		// Descriptions only mark the code, commands are code already
+		if code && !inCode && hasText(tokens) {
+			fmt.Fprint(out, "<code>")
+			inCode = true
+		}
+
+		if !code && inCode {
+			fmt.Fprint(out, "</code>")
+			inCode = false
+		}
+
+		switch token.Kind {
+		case TextToken, CodeToken:
+			fmt.Fprint(out, html.EscapeString(token.Text))
+
+		case PlaceholderToken:
+			fmt.Fprint(out, "<var class=\"tldr-placeholder\">", html.EscapeString(token.Text), "</var>")
+		}
+
+		if inCode && i == len(tokens)-1 {
+			fmt.Fprint(out, "</code>")
+		}
+	}
+}
+
+// hasText checks if any of the tokens is ordinary text
+func hasText(tokens []Token) bool {
+	for _, token := range tokens {
+		if token.Kind == TextToken {
+			return true
+		}
+	}
+
+	return false
+}
+
+// printHTMLLink writes the link, only web links are made clickable so a
+// page can't sneak in javascript: or other dangerous URLs
+func printHTMLLink(out io.Writer, link string) {
+	u, err := url.Parse(link)
+
+	if err != nil || (!strings.EqualFold(u.Scheme, "http") && !strings.EqualFold(u.Scheme, "https")) {
+		fmt.Fprint(out, html.EscapeString(link))
+		return
+	}
+
+	fmt.Fprint(out, "<a href=\"", html.EscapeString(link), "\">", html.EscapeString(link), "</a>")
+}
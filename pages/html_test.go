@@ -0,0 +1,72 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrintHTML(t *testing.T) {
+	source := "# a<b\n\n" +
+		"> Uses `x & y`.\n" +
+		"> More information: <https://example.com/?a=1&b=2>.\n\n" +
+		"- Run <it>:\n\n" +
+		"`a {{x<y}}`\n"
+
+	want := "<article class=\"tldr-page\">\n" +
+		"<h1>a&lt;b</h1>\n" +
+		"<p class=\"tldr-description\">Uses <code>x &amp; y</code>.</p>\n" +
+		"<p class=\"tldr-description tldr-more-info\">More information: " +
+		"<a href=\"https://example.com/?a=1&amp;b=2\">https://example.com/?a=1&amp;b=2</a>.</p>\n" +
+		"<ul class=\"tldr-examples\">\n" +
+		"<li class=\"tldr-example\">\n<p>Run &lt;it&gt;:</p>\n" +
+		"<pre><code>a <var class=\"tldr-placeholder\">x&lt;y</var></code></pre>\n" +
+		"</li>\n</ul>\n</article>\n"
+
+	var out bytes.Buffer
+	PrintHTML(&out, []byte(source))
+
+	if out.String() != want {
+		t.Errorf("PrintHTML() =\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestPrintHTMLLinks(t *testing.T) {
+	for _, link := range []string{"javascript:alert(1)", "data:text/html,hi", "/relative"} {
+		var out bytes.Buffer
+		PrintHTML(&out, []byte("# a\n\n> More information: <"+link+">.\n"))
+
+		if strings.Contains(out.String(), "<a ") {
+			t.Errorf("link to %q is clickable:\n%s", link, out.String())
+		}
+	}
+}
+
+func TestPrintHTMLDocument(t *testing.T) {
+	var out bytes.Buffer
+	PrintHTMLDocument(&out, []byte("# ls\n"), []byte("# tar\n"))
+	document := out.String()
+
+	if !strings.Contains(document, "<title>ls - tldr</title>") {
+		t.Errorf("document isn't titled after the first page:\n%s", document)
+	}
+
+	if strings.Count(document, "<article") != 2 {
+		t.Errorf("document doesn't contain both pages:\n%s", document)
+	}
+}
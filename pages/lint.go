@@ -0,0 +1,203 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// Rules checked by Lint, these mostly follow the style guide of the
+// tldr-pages project
+const (
+	RuleSyntax             = "TL001" // the page can't be parsed
+	RuleTitleFirst         = "TL002" // the title is the first line
+	RuleMarkerSpace        = "TL003" // #, > and - are followed by a single space
+	RuleDescriptionPeriod  = "TL004" // description lines end with a period
+	RuleExampleColon       = "TL005" // example descriptions end with a colon
+	RuleTrailingWhitespace = "TL006" // lines don't end with whitespace
+	RuleFinalNewline       = "TL007" // the file ends with a single newline
+	RuleOrder              = "TL008" // the description comes before the examples
+	RuleBlankLines         = "TL009" // sections are separated by a single blank line
+	RuleMissingDescription = "TL010" // there is a description
+	RuleIndentation        = "TL011" // lines aren't indented
+)
+
+// LintError is a violation of one of the rules
+type LintError struct {
+	Pos     Position
+	Rule    string
+	Message string
+}
+
+func (err *LintError) Error() string {
+	return err.Pos.String() + ": " + err.Rule + " " + err.Message
+}
+
+// Lint checks a page against the style rules of the tldr pages, all
+// violations are returned in the order in which they appear
+func Lint(source []byte) []*LintError {
+	l := linter{}
+
+	// Start with the problems the parser finds
+	if _, err := Parse(source); err != nil {
+		for _, parseErr := range err.(ParseErrors) {
+			l.report(parseErr.Pos, RuleSyntax, parseErr.Message)
+		}
+	}
+
+	lines := strings.Split(string(source), "\n")
+
+	for i, line := range lines {
+		l.checkLine(i+1, line)
+	}
+
+	l.finish(source, lines)
+
+	sort.SliceStable(l.errors, func(i, j int) bool {
+		a, b := l.errors[i].Pos, l.errors[j].Pos
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
+
+	return l.errors
+}
+
+// linter keeps track of the state while linting a page
+type linter struct {
+	errors []*LintError
+
+	// previous is the marker of the last non blank line, or 0 if there is none
+	previous byte
+
+	// titlePos is the position of the title, if found
+	titlePos *Position
+
+	// blanks is the number of blank lines since the previous line
+	blanks int
+
+	// hasDescription is set once we encounter a description
+	hasDescription bool
+}
+
+// report records a violation
+func (l *linter) report(pos Position, rule, message string) {
+	l.errors = append(l.errors, &LintError{pos, rule, message})
+}
+
+// checkLine checks a single line of the page
+func (l *linter) checkLine(lineNo int, line string) {
+	// Whitespace at the end of lines, which includes
+	// the carriage returns of Windows line endings
+	if trimmed := strings.TrimRight(line, " \t\r"); len(trimmed) < len(line) {
+		l.report(position(lineNo, line, len(trimmed)), RuleTrailingWhitespace, "trailing whitespace")
+		line = trimmed
+	}
+
+	content := strings.TrimLeft(line, " \t")
+
+	if len(content) == 0 {
+		l.blanks++
+		return
+	}
+
+	indent := len(line) - len(content)
+	pos := position(lineNo, line, indent)
+
+	if indent > 0 {
+		l.report(Position{lineNo, 1}, RuleIndentation, "line should not be indented")
+	}
+
+	marker := content[0]
+
+	// Markers are followed by a single space
+	if marker == '#' || marker == '>' || marker == '-' {
+		if len(content) < 2 || content[1] != ' ' || (len(content) > 2 && content[2] == ' ') {
+			l.report(position(lineNo, line, indent+1), RuleMarkerSpace,
+				"'"+string(marker)+"' should be followed by a single space")
+		}
+	}
+
+	end := position(lineNo, line, len(line))
+
+	switch marker {
+	case '#':
+		if l.titlePos == nil {
+			l.titlePos = &pos
+
+			if lineNo != 1 {
+				l.report(pos, RuleTitleFirst, "the title should be on the first line")
+			}
+		}
+
+	case '>':
+		l.hasDescription = true
+
+		if !strings.HasSuffix(content, ".") {
+			l.report(end, RuleDescriptionPeriod, "description should end with a period")
+		}
+
+		if l.previous == '-' || l.previous == '`' {
+			l.report(pos, RuleOrder, "the description should come before the examples")
+		}
+
+	case '-':
+		if !strings.HasSuffix(content, ":") {
+			l.report(end, RuleExampleColon, "example description should end with a colon")
+		}
+	}
+
+	// Check the blank lines in front of this one
+	switch {
+	case l.previous == 0:
+		// Leading blank lines are covered by RuleTitleFirst
+
+	case l.previous == '>' && marker == '>':
+		if l.blanks > 0 {
+			l.report(pos, RuleBlankLines, "description lines should not be separated by blank lines")
+		}
+
+	case l.blanks == 0:
+		l.report(pos, RuleBlankLines, "expected a blank line before this line")
+
+	case l.blanks > 1:
+		l.report(pos, RuleBlankLines, "expected a single blank line before this line")
+	}
+
+	l.previous = marker
+	l.blanks = 0
+}
+
+// finish checks the page as a whole once all lines are checked
+func (l *linter) finish(source []byte, lines []string) {
+	if l.titlePos != nil && !l.hasDescription {
+		l.report(*l.titlePos, RuleMissingDescription, "expected a description")
+	}
+
+	if len(source) == 0 {
+		return
+	}
+
+	last := lines[len(lines)-1]
+	end := position(len(lines), last, len(last))
+
+	if !bytes.HasSuffix(source, []byte("\n")) {
+		l.report(end, RuleFinalNewline, "file should end with a newline")
+
+	} else if bytes.HasSuffix(bytes.TrimRight(source, " \t\r"), []byte("\n\n")) {
+		l.report(end, RuleFinalNewline, "file should end with a single newline")
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintValidPage(t *testing.T) {
+	source := "# tar\n\n" +
+		"> Archiving utility.\n" +
+		"> More information: <https://www.gnu.org/software/tar>.\n\n" +
+		"- Create an archive:\n\n" +
+		"`tar cf {{target.tar}} {{file1}}`\n"
+
+	if errs := Lint([]byte(source)); len(errs) != 0 {
+		t.Errorf("valid page has problems: %v", errs)
+	}
+}
+
+func TestLintRules(t *testing.T) {
+	tests := []struct {
+		source string
+		rules  []string
+	}{
+		{"> Utility.\n\n# tar\n", []string{RuleTitleFirst}},
+		{"#  tar\n\n> Utility.\n", []string{RuleMarkerSpace}},
+		{"# tar\n\n> Utility\n", []string{RuleDescriptionPeriod}},
+		{"# tar\n\n> Utility.\n\n- Create an archive\n\n`tar`\n", []string{RuleExampleColon}},
+		{"# tar \n\n> Utility.\n", []string{RuleTrailingWhitespace}},
+		{"# tar\n\n> Utility.", []string{RuleFinalNewline}},
+		{"# tar\n\n- Create:\n\n`tar`\n\n> Utility.\n", []string{RuleOrder}},
+		{"# tar\n> Utility.\n", []string{RuleBlankLines}},
+		{"# tar\n\n- Create:\n\n`tar`\n", []string{RuleMissingDescription}},
+		{"# tar\n\n  > Utility.\n", []string{RuleIndentation}},
+	}
+
+	for _, test := range tests {
+		var rules []string
+
+		for _, err := range Lint([]byte(test.source)) {
+			rules = append(rules, err.Rule)
+		}
+
+		if !reflect.DeepEqual(rules, test.rules) {
+			t.Errorf("Lint(%q) broke %v, want %v", test.source, rules, test.rules)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ManSection is the man page section the pages are written for
+const ManSection = "7"
+
+// roffEscaper escapes the characters roff would otherwise interpret, minus
+// signs are escaped so commands can be copied from the formatted page
+var roffEscaper = strings.NewReplacer(`\`, `\e`, `-`, `\-`)
+
+// PrintMan writes the page as a roff man page
+func PrintMan(out io.Writer, source []byte) {
+	page, _ := Parse(source)
+	printManPage(out, page)
+}
+
+func printManPage(out io.Writer, page *Page) {
+	fmt.Fprintf(out, ".TH \"%s\" \"%s\" \"\" \"tldr\" \"tldr pages\"\n",
+		strings.ReplaceAll(roffEscape(strings.ToUpper(page.Title)), `"`, `\(dq`), ManSection)
+
+	// The name section is used by whatis and apropos
+	fmt.Fprint(out, ".SH NAME\n", roffLine(roffEscape(page.Title)))
+
+	if len(page.Description) > 0 {
+		fmt.Fprint(out, ` \- `, roffEscape(page.Description[0].Text()))
+	}
+
+	fmt.Fprintln(out)
+
+	if len(page.Description) > 0 || page.MoreInfo != "" {
+		fmt.Fprint(out, ".SH DESCRIPTION\n")
+
+		for _, line := range page.Description {
+			fmt.Fprint(out, roffLine(roffTokens(line.Tokens, `\fR`)), "\n.br\n")
+		}
+
+		if page.MoreInfo != "" {
+			fmt.Fprint(out, ".PP\n", moreInfoPrefix, ` \fI`, roffEscape(page.MoreInfo), "\\fR.\n")
+		}
+	}
+
+	if len(page.Examples) > 0 {
+		fmt.Fprint(out, ".SH EXAMPLES\n")
+
+		for _, ex := range page.Examples {
+			before, after := ex.splitNotes()
+
+			fmt.Fprint(out, ".TP\n", roffLine(roffTokens(ex.Description.Tokens, `\fR`)), "\n")
+
+			for _, note := range before {
+				fmt.Fprint(out, roffLine(roffTokens(note.Tokens, `\fR`)), "\n.br\n")
+			}
+
+			fmt.Fprint(out, roffLine(roffTokens(ex.Command.Tokens, `\fB`)), "\\fR\n")
+
+			for _, note := range after {
+				fmt.Fprint(out, ".br\n", roffLine(roffTokens(note.Tokens, `\fR`)), "\n")
+			}
+		}
+	}
+}
+
+// roffTokens converts the tokens to roff, code is bold and placeholders are
+// italic, text is set in the given font
+func roffTokens(tokens []Token, textFont string) string {
+	var res strings.Builder
+
+	for _, token := range tokens {
+		switch token.Kind {
+		case TextToken:
+			res.WriteString(textFont)
+
+		case CodeToken:
+			res.WriteString(`\fB`)
+
+		case PlaceholderToken:
+			res.WriteString(`\fI`)
+		}
+
+		res.WriteString(roffEscape(token.Text))
+	}
+
+	return res.String()
+}
+
+// roffEscape escapes the text for use in roff
+func roffEscape(text string) string {
+	return roffEscaper.Replace(text)
+}
+
+// roffLine makes sure a line of text isn't taken for a request
+func roffLine(line string) string {
+	if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+		return `\&` + line
+	}
+
+	return line
+}
@@ -0,0 +1,66 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrintMan(t *testing.T) {
+	source := "# git-log\n\n" +
+		"> Show commits.\n" +
+		"> .See the \\ manual.\n" +
+		"> More information: <https://git-scm.com/docs/git-log>.\n\n" +
+		"- Show the log with `--oneline`:\n\n" +
+		"`git log --oneline {{path\\to\\dir}}`\n"
+
+	want := `.TH "GIT\-LOG" "7" "" "tldr" "tldr pages"
+.SH NAME
+git\-log \- Show commits.
+.SH DESCRIPTION
+\fRShow commits.
+.br
+\fR.See the \e manual.
+.br
+.PP
+More information: \fIhttps://git\-scm.com/docs/git\-log\fR.
+.SH EXAMPLES
+.TP
+\fRShow the log with \fB\-\-oneline\fR:
+\fBgit log \-\-oneline \fIpath\eto\edir\fR
+`
+
+	var out bytes.Buffer
+	PrintMan(&out, []byte(source))
+
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestPrintManControlLines(t *testing.T) {
+	// Lines starting with a dot or quote would be taken for requests
+	for _, title := range []string{".hidden", "'quoted"} {
+		var out bytes.Buffer
+		PrintMan(&out, []byte("# "+title+"\n"))
+
+		if !strings.Contains(out.String(), "\n.SH NAME\n\\&"+title+"\n") {
+			t.Errorf("%s isn't escaped:\n%s", title, out.String())
+		}
+	}
+}
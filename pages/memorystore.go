@@ -0,0 +1,109 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"sort"
+)
+
+// MemoryStore is a PageStore keeping all pages in memory, this is
+// mostly useful for testing
+type MemoryStore struct {
+	// pages maps a language, platform and name to a page
+	pages map[string]map[string]map[string][]byte
+
+	// meta contains the metadata
+	meta map[string]string
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		pages: make(map[string]map[string]map[string][]byte),
+		meta:  make(map[string]string),
+	}
+}
+
+// Add adds a page to the store, replacing any page with the same name
+func (store *MemoryStore) Add(language, platform, name string, page []byte) {
+	if store.pages[language] == nil {
+		store.pages[language] = make(map[string]map[string][]byte)
+	}
+
+	if store.pages[language][platform] == nil {
+		store.pages[language][platform] = make(map[string][]byte)
+	}
+
+	store.pages[language][platform][name] = page
+}
+
+// SetMetadata sets the value of a metadata key
+func (store *MemoryStore) SetMetadata(key, value string) {
+	store.meta[key] = value
+}
+
+// Page implements PageStore
+func (store *MemoryStore) Page(language, platform, name string) ([]byte, error) {
+	return store.pages[language][platform][name], nil
+}
+
+// Names implements PageStore
+func (store *MemoryStore) Names(language, platform string) ([]string, error) {
+	var names []string
+
+	for name := range store.pages[language][platform] {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// Platforms implements PageStore
+func (store *MemoryStore) Platforms() ([]string, error) {
+	var platforms []string
+
+	for platform := range store.pages[English] {
+		if platform != Common {
+			platforms = append(platforms, platform)
+		}
+	}
+
+	sort.Strings(platforms)
+	return platforms, nil
+}
+
+// Languages implements PageStore
+func (store *MemoryStore) Languages() ([]string, error) {
+	var languages []string
+
+	// Without english pages the store is considered empty
+	if store.pages[English] == nil {
+		return nil, nil
+	}
+
+	for language := range store.pages {
+		languages = append(languages, language)
+	}
+
+	sort.Strings(languages)
+	return languages, nil
+}
+
+// Metadata implements PageStore
+func (store *MemoryStore) Metadata(key string) (string, error) {
+	return store.meta[key], nil
+}
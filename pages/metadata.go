@@ -0,0 +1,146 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"os"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// metaBucket is the name of the bucket containing information about the database
+var metaBucket = []byte("meta")
+
+// Keys in the metadata bucket
+const (
+	// metaSource is the location the pages were fetched from
+	metaSource = "source"
+
+	// metaETag is the ETag header of the downloaded archive
+	metaETag = "etag"
+
+	// metaLastModified is the Last-Modified header of the downloaded archive
+	metaLastModified = "last-modified"
+
+	// metaUpdated is the time of the last successful update in RFC 3339 format
+	metaUpdated = "updated"
+
+	// metaChecked is the time of the last update attempt in RFC 3339
+	// format, including those which failed
+	metaChecked = "checked"
+
+	// metaCodec is the codec in which the pages are stored
+	metaCodec = "codec"
+
+	// metaSchema is the version of the layout of the database
+	metaSchema = "schema"
+)
+
+// metadata contains information about the pages in a database
+type metadata map[string]string
+
+// readMetadata reads the metadata bucket, older databases don't have one
+// in which case the metadata is empty
+func readMetadata(tx *bbolt.Tx) metadata {
+	meta := make(metadata)
+
+	bucket := tx.Bucket(metaBucket)
+
+	if bucket == nil {
+		return meta
+	}
+
+	bucket.ForEach(
+		func(key, value []byte) error {
+			meta[string(key)] = string(value)
+			return nil
+		})
+
+	return meta
+}
+
+// writeMetadata adds the given keys to the metadata bucket
+func writeMetadata(tx *bbolt.Tx, meta metadata) error {
+	bucket, err := tx.CreateBucketIfNotExists(metaBucket)
+
+	if err != nil {
+		return err
+	}
+
+	for key, value := range meta {
+		err = bucket.Put([]byte(key), []byte(value))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadMetadata reads the metadata of the database at the given path,
+// if there is no database yet the metadata is empty
+func loadMetadata(dbPath string) (metadata, error) {
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return make(metadata), nil
+	}
+
+	database, err := bbolt.Open(dbPath, 0600,
+		&bbolt.Options{
+			Timeout:  1 * time.Second,
+			ReadOnly: true,
+		})
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer database.Close()
+
+	var meta metadata
+	err = database.View(
+		func(tx *bbolt.Tx) error {
+			meta = readMetadata(tx)
+			return nil
+		})
+
+	return meta, err
+}
+
+// storeMetadata adds the given keys to the metadata of the database at the given path
+func storeMetadata(dbPath string, meta metadata) error {
+	database, err := bbolt.Open(dbPath, 0600,
+		&bbolt.Options{
+			Timeout: 1 * time.Second,
+		})
+
+	if err != nil {
+		return err
+	}
+
+	defer database.Close()
+
+	return database.Update(
+		func(tx *bbolt.Tx) error {
+			return writeMetadata(tx, meta)
+		})
+}
+
+// now returns the current time in the format used in the metadata
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
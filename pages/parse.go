@@ -0,0 +1,418 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// TokenKind is the kind of a Token
+type TokenKind int
+
+const (
+	// TextToken is ordinary text
+	TextToken TokenKind = iota
+
+	// CodeToken is text which has to be typed literally
+	CodeToken
+
+	// PlaceholderToken is a part of a command the user has to fill in
+	PlaceholderToken
+)
+
+// Position is a location in a page, both the line and the column start at 1
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (pos Position) String() string {
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+}
+
+// tokenKindNames are the names of the token kinds used in JSON
+var tokenKindNames = map[TokenKind]string{
+	TextToken:        "text",
+	CodeToken:        "code",
+	PlaceholderToken: "placeholder",
+}
+
+// MarshalText gives the name of the kind
+func (kind TokenKind) MarshalText() ([]byte, error) {
+	return []byte(tokenKindNames[kind]), nil
+}
+
+// Token is a piece of text in a page
+type Token struct {
+	Kind TokenKind `json:"type"`
+	Text string    `json:"text"`
+	Pos  Position  `json:"-"`
+}
+
+// Line is a line of text in a page
+type Line struct {
+	Tokens []Token
+	Pos    Position
+}
+
+// Text returns the text of the line without any markup
+func (line Line) Text() string {
+	var text strings.Builder
+
+	for _, token := range line.Tokens {
+		text.WriteString(token.Text)
+	}
+
+	return text.String()
+}
+
+// MarshalJSON gives both the text and the tokens of the line
+func (line Line) MarshalJSON() ([]byte, error) {
+	tokens := line.Tokens
+
+	if tokens == nil {
+		tokens = []Token{}
+	}
+
+	return json.Marshal(struct {
+		Text   string  `json:"text"`
+		Tokens []Token `json:"tokens"`
+	}{line.Text(), tokens})
+}
+
+// Example is a single example in a page
+type Example struct {
+	// Description describes what the example does
+	Description Line `json:"description"`
+
+	// Command is the example itself, it only contains
+	// CodeTokens and PlaceholderTokens
+	Command Line `json:"command"`
+
+	// Notes are lines in the example which aren't part of the syntax,
+	// like the paragraphs of a draft, kept as text in the order they appear
+	Notes []Line `json:"notes,omitempty"`
+}
+
+// splitNotes splits the notes in those in front of and after the command
+func (ex Example) splitNotes() (before, after []Line) {
+	for i, note := range ex.Notes {
+		if ex.Command.Pos.Line != 0 && note.Pos.Line > ex.Command.Pos.Line {
+			return ex.Notes[:i], ex.Notes[i:]
+		}
+	}
+
+	return ex.Notes, nil
+}
+
+// Page is a parsed tldr page
+type Page struct {
+	// Title is the name of the command
+	Title    string   `json:"title"`
+	TitlePos Position `json:"-"`
+
+	// Description describes the command, the link to
+	// more information is not part of it
+	Description []Line `json:"description"`
+
+	// MoreInfo is the link to more information, if any
+	MoreInfo    string   `json:"more_information,omitempty"`
+	MoreInfoPos Position `json:"-"`
+
+	// Examples are the examples in the order they appear
+	Examples []Example `json:"examples"`
+}
+
+// ParseError is a problem found while parsing a page
+type ParseError struct {
+	Pos     Position
+	Message string
+}
+
+func (err *ParseError) Error() string {
+	return err.Pos.String() + ": " + err.Message
+}
+
+// ParseErrors are all problems found while parsing a page
+type ParseErrors []*ParseError
+
+func (errs ParseErrors) Error() string {
+	messages := make([]string, len(errs))
+
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// moreInfoPrefix starts the description line containing the link to more information
+const moreInfoPrefix = "More information:"
+
+// Parse parses a tldr page, if the page isn't valid as much of it as possible
+// is still returned together with ParseErrors describing the problems
+func Parse(source []byte) (*Page, error) {
+	p := parser{page: &Page{}}
+
+	for i, line := range strings.Split(string(source), "\n") {
+		p.parseLine(i+1, strings.TrimSuffix(line, "\r"))
+	}
+
+	p.finish()
+
+	if len(p.errors) > 0 {
+		return p.page, p.errors
+	}
+
+	return p.page, nil
+}
+
+// parser keeps track of the state while parsing a page
+type parser struct {
+	page   *Page
+	errors ParseErrors
+
+	// pending is an example which is still waiting for its command
+	pending *Example
+}
+
+// errorf records a problem at the given position
+func (p *parser) errorf(pos Position, format string, args ...interface{}) {
+	p.errors = append(p.errors, &ParseError{pos, fmt.Sprintf(format, args...)})
+}
+
+// position returns the position of the byte at the given offset in the line
+func position(lineNo int, line string, offset int) Position {
+	return Position{lineNo, utf8.RuneCountInString(line[:offset]) + 1}
+}
+
+// parseLine parses a single line of the page
+func (p *parser) parseLine(lineNo int, line string) {
+	// Surrounding spaces don't matter
+	start := len(line) - len(strings.TrimLeft(line, " \t"))
+	end := len(strings.TrimRight(line, " \t"))
+
+	// Skip empty lines
+	if start >= end {
+		return
+	}
+
+	pos := position(lineNo, line, start)
+
+	// Where does the content after the marker start?
+	content := start + 1
+	for content < end && line[content] == ' ' {
+		content++
+	}
+
+	switch line[start] {
+	case '#':
+		if p.page.Title != "" {
+			p.errorf(pos, "more than one title")
+			return
+		}
+
+		p.page.Title = line[content:end]
+		p.page.TitlePos = pos
+
+	case '>':
+		text := line[content:end]
+
+		if strings.HasPrefix(text, moreInfoPrefix) {
+			p.parseMoreInfo(lineNo, line, content, end)
+			return
+		}
+
+		p.page.Description = append(p.page.Description,
+			Line{p.tokenizeText(lineNo, line, content, end), pos})
+
+	case '-':
+		p.flushPending()
+
+		p.pending = &Example{
+			Description: Line{p.tokenizeText(lineNo, line, content, end), pos},
+		}
+
+	case '`':
+		// The command is wrapped in backticks
+		if end-start < 2 || line[end-1] != '`' {
+			p.errorf(pos, "unterminated command")
+			end++
+		}
+
+		if p.pending == nil {
+			p.errorf(pos, "command without description")
+			p.pending = &Example{}
+		}
+
+		p.pending.Command = Line{p.tokenizeCode(lineNo, line, start+1, end-1), pos}
+		p.page.Examples = append(p.page.Examples, *p.pending)
+		p.pending = nil
+
+	default:
+		p.errorf(pos, "expected a title, description, example or command")
+		p.addText(Line{[]Token{{TextToken, line[start:end], pos}}, pos})
+	}
+}
+
+// addText keeps a line which isn't part of the syntax as text where it was
+// found, so drafts can be shown without losing anything
+func (p *parser) addText(line Line) {
+	switch {
+	case p.pending != nil:
+		p.pending.Notes = append(p.pending.Notes, line)
+
+	case len(p.page.Examples) > 0:
+		last := &p.page.Examples[len(p.page.Examples)-1]
+		last.Notes = append(last.Notes, line)
+
+	default:
+		p.page.Description = append(p.page.Description, line)
+	}
+}
+
+// parseMoreInfo parses the line containing the link to more information
+func (p *parser) parseMoreInfo(lineNo int, line string, start, end int) {
+	pos := position(lineNo, line, start)
+	text := line[start:end]
+
+	if p.page.MoreInfo != "" {
+		p.errorf(pos, "more than one link to more information")
+		return
+	}
+
+	// The link is wrapped in angle brackets
+	open := strings.Index(text, "<")
+	close := strings.LastIndex(text, ">")
+
+	if open < 0 || close < open {
+		p.errorf(pos, "link to more information should be wrapped in < and >")
+		return
+	}
+
+	p.page.MoreInfo = text[open+1 : close]
+	p.page.MoreInfoPos = position(lineNo, line, start+open+1)
+}
+
+// tokenizeText splits text in the line between start and end into text and
+// the code between backticks, which is further split by tokenizeCode
+func (p *parser) tokenizeText(lineNo int, line string, start, end int) []Token {
+	var tokens []Token
+
+	for start < end {
+		tick := strings.IndexByte(line[start:end], '`')
+
+		if tick < 0 {
+			tokens = append(tokens, Token{TextToken, line[start:end], position(lineNo, line, start)})
+			break
+		}
+
+		if tick > 0 {
+			tokens = append(tokens, Token{TextToken, line[start : start+tick], position(lineNo, line, start)})
+		}
+
+		// Find the closing backtick
+		codeStart := start + tick + 1
+		closing := strings.IndexByte(line[codeStart:end], '`')
+
+		if closing < 0 {
+			p.errorf(position(lineNo, line, start+tick), "unbalanced backtick")
+			closing = end - codeStart
+		}
+
+		tokens = append(tokens, p.tokenizeCode(lineNo, line, codeStart, codeStart+closing)...)
+		start = codeStart + closing + 1
+	}
+
+	return tokens
+}
+
+// tokenizeCode splits the code in the line between start and end into
+// literal code and the placeholders between {{ and }}
+func (p *parser) tokenizeCode(lineNo int, line string, start, end int) []Token {
+	var tokens []Token
+
+	for start < end {
+		open := strings.Index(line[start:end], "{{")
+
+		// Only code left?
+		if open < 0 {
+			p.checkClosingBraces(lineNo, line, start, end)
+			tokens = append(tokens, Token{CodeToken, line[start:end], position(lineNo, line, start)})
+			break
+		}
+
+		if open > 0 {
+			p.checkClosingBraces(lineNo, line, start, start+open)
+			tokens = append(tokens, Token{CodeToken, line[start : start+open], position(lineNo, line, start)})
+		}
+
+		// Find the end of the placeholder
+		placeholderStart := start + open + 2
+		closing := strings.Index(line[placeholderStart:end], "}}")
+
+		if closing < 0 {
+			p.errorf(position(lineNo, line, start+open), "unbalanced {{")
+			closing = end - placeholderStart
+		}
+
+		// Braces right before the closing ones belong to the placeholder
+		for placeholderStart+closing+2 < end && line[placeholderStart+closing+2] == '}' {
+			closing++
+		}
+
+		// Empty placeholders are no-ops
+		if closing > 0 {
+			tokens = append(tokens, Token{
+				PlaceholderToken,
+				line[placeholderStart : placeholderStart+closing],
+				position(lineNo, line, placeholderStart),
+			})
+		}
+
+		start = placeholderStart + closing + 2
+	}
+
+	return tokens
+}
+
+// checkClosingBraces reports closing braces in code outside a placeholder
+func (p *parser) checkClosingBraces(lineNo int, line string, start, end int) {
+	if closing := strings.Index(line[start:end], "}}"); closing >= 0 {
+		p.errorf(position(lineNo, line, start+closing), "unbalanced }}")
+	}
+}
+
+// flushPending adds an example which never got a command to the page
+func (p *parser) flushPending() {
+	if p.pending != nil {
+		p.errorf(p.pending.Description.Pos, "example without command")
+		p.page.Examples = append(p.page.Examples, *p.pending)
+		p.pending = nil
+	}
+}
+
+// finish checks the page once all lines are parsed
+func (p *parser) finish() {
+	p.flushPending()
+
+	if p.page.Title == "" {
+		p.errorf(Position{1, 1}, "missing title")
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	source := "# tar\n\n" +
+		"> Archiving utility.\n" +
+		"> More information: <https://www.gnu.org/software/tar>.\n\n" +
+		"- Create an archive from `{{files}}`:\n\n" +
+		"`tar cf {{target.tar}} {{file1}}`\n"
+
+	page, err := Parse([]byte(source))
+
+	if err != nil {
+		t.Fatal("unexpected errors:", err)
+	}
+
+	if page.Title != "tar" || page.TitlePos != (Position{1, 1}) {
+		t.Errorf("got title %q at %v", page.Title, page.TitlePos)
+	}
+
+	wantLine := []Line{{[]Token{{TextToken, "Archiving utility.", Position{3, 3}}}, Position{3, 1}}}
+
+	if !reflect.DeepEqual(page.Description, wantLine) {
+		t.Errorf("got description %v, want %v", page.Description, wantLine)
+	}
+
+	if page.MoreInfo != "https://www.gnu.org/software/tar" || page.MoreInfoPos != (Position{4, 22}) {
+		t.Errorf("got more information %q at %v", page.MoreInfo, page.MoreInfoPos)
+	}
+
+	if len(page.Examples) != 1 {
+		t.Fatalf("got %d examples, want 1", len(page.Examples))
+	}
+
+	wantDescription := []Token{
+		{TextToken, "Create an archive from ", Position{6, 3}},
+		{PlaceholderToken, "files", Position{6, 29}},
+		{TextToken, ":", Position{6, 37}},
+	}
+
+	if got := page.Examples[0].Description.Tokens; !reflect.DeepEqual(got, wantDescription) {
+		t.Errorf("got description tokens %v, want %v", got, wantDescription)
+	}
+
+	wantCommand := []Token{
+		{CodeToken, "tar cf ", Position{8, 2}},
+		{PlaceholderToken, "target.tar", Position{8, 11}},
+		{CodeToken, " ", Position{8, 23}},
+		{PlaceholderToken, "file1", Position{8, 26}},
+	}
+
+	if got := page.Examples[0].Command.Tokens; !reflect.DeepEqual(got, wantCommand) {
+		t.Errorf("got command tokens %v, want %v", got, wantCommand)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		errors []string
+	}{
+		{"> No title.\n", []string{"1:1: missing title"}},
+		{"# a\n\n`a`\n", []string{"3:1: command without description"}},
+		{"# a\n\n- Example:\n", []string{"3:1: example without command"}},
+		{"# a\n\n- Example:\n\n`a {{b`\n", []string{"5:4: unbalanced {{"}},
+		{"# a\n\n- Example:\n\n`a b}}`\n", []string{"5:5: unbalanced }}"}},
+		{"# a\n\n- Use `a:\n\n`a`\n", []string{"3:7: unbalanced backtick"}},
+		{"# a\n\n- Example:\n\n`a\n", []string{"5:1: unterminated command"}},
+		{"# a\n# b\n", []string{"2:1: more than one title"}},
+		{"# a\nplain text\n", []string{"2:1: expected a title, description, example or command"}},
+	}
+
+	for _, test := range tests {
+		_, err := Parse([]byte(test.source))
+
+		var got []string
+		if err != nil {
+			got = strings.Split(err.Error(), "\n")
+		}
+
+		if !reflect.DeepEqual(got, test.errors) {
+			t.Errorf("Parse(%q) reported %q, want %q", test.source, got, test.errors)
+		}
+	}
+}
+
+func TestParseKeepsUnknownLines(t *testing.T) {
+	source := "# x\n\n" +
+		"> Desc.\n\n" +
+		"intro text\n\n" +
+		"- Do it:\n\n" +
+		"between text\n\n" +
+		"`x`\n\n" +
+		"after text\n"
+
+	page, err := Parse([]byte(source))
+
+	if err == nil {
+		t.Error("unknown lines aren't reported")
+	}
+
+	text := func(s string, line int) Line {
+		pos := Position{line, 1}
+		return Line{[]Token{{TextToken, s, pos}}, pos}
+	}
+
+	wantDescription := []Line{
+		{[]Token{{TextToken, "Desc.", Position{3, 3}}}, Position{3, 1}},
+		text("intro text", 5),
+	}
+
+	if !reflect.DeepEqual(page.Description, wantDescription) {
+		t.Errorf("got description %v, want %v", page.Description, wantDescription)
+	}
+
+	if len(page.Examples) != 1 {
+		t.Fatalf("got %d examples, want 1", len(page.Examples))
+	}
+
+	before, after := page.Examples[0].splitNotes()
+
+	if !reflect.DeepEqual(before, []Line{text("between text", 9)}) || !reflect.DeepEqual(after, []Line{text("after text", 13)}) {
+		t.Errorf("got notes %v before and %v after the command", before, after)
+	}
+}
@@ -16,136 +16,170 @@
 package pages
 
 import (
-	"bytes"
 	"fmt"
-	"os"
+	"io"
 	"strings"
-
-	"golang.org/x/crypto/ssh/terminal"
 )
 
-const (
-	normal      = colDefault
-	heading     = normal | modBold
-	note        = normal
-	description = normal
-	verbatim    = colBrightRed
-	example     = normal | modItalic
-)
+// PrettyPrintOptions configures how pages are formatted for a terminal
+type PrettyPrintOptions struct {
+	// Color enables colors and other ANSI escape codes, without them
+	// the page is shown as plain text
+	Color bool
+
+	// Theme contains the styles to use, nil means DefaultTheme
+	Theme *Theme
 
-func emptyDatabase() {
-	// The database is empty
-	fmt.Fprint(os.Stderr, "\n  ", "The database is empty.")
-	fmt.Fprint(os.Stderr, "\n  ", "You can try updating the database using ", colorize("tldr --update", verbatim), ".\n\n")
+	// Width is the number of columns at which lines are wrapped,
+	// zero or less means lines are never wrapped
+	Width int
 }
 
-func pageUnavailable(command string) {
-	// The page is not in the database
-	fmt.Print("\n  ", colorize(command, heading), " documentation is not available.")
-	fmt.Print("\n  ", "You can try updating the database using ", colorize("tldr --update", verbatim), ".")
-	fmt.Print("\n  ", "Or add a page yourself to https://github.com/tldr-pages/tldr.", "\n\n")
+// prettyPrinter writes text formatted for a terminal
+type prettyPrinter struct {
+	out     io.Writer
+	options PrettyPrintOptions
+	theme   *Theme
 }
 
-func prettyPrint(page []byte) {
-	// Don't pretty print to TTY
-	if !terminal.IsTerminal(int(os.Stdout.Fd())) {
-		fmt.Print(string(page))
-		return
+// newPrettyPrinter returns a prettyPrinter writing to out
+func newPrettyPrinter(out io.Writer, options PrettyPrintOptions) *prettyPrinter {
+	theme := options.Theme
+
+	if theme == nil {
+		theme = &DefaultTheme
 	}
 
-	// Add an blank line in front of the page
-	fmt.Println()
+	return &prettyPrinter{out, options, theme}
+}
 
-	// Pretty print the lines in the page
-	for _, lineB := range bytes.Split(page, []byte{'\n'}) {
-		line := string(bytes.TrimSpace(lineB))
+// print writes the text in the given style
+func (p *prettyPrinter) print(text string, style Style) {
+	if p.options.Color {
+		fmt.Fprint(p.out, colorize(text, style))
+	} else {
+		fmt.Fprint(p.out, text)
+	}
+}
 
-		if len(line) == 0 {
-			// Skip empty lines
-			continue
-		}
+// EmptyDatabase tells the user the database is empty
+func EmptyDatabase(out io.Writer, options PrettyPrintOptions) {
+	p := newPrettyPrinter(out, options)
 
-		switch line[0] {
-		case '#':
-			fmt.Print("  ")
-			processLine(line[1:], heading)
+	fmt.Fprint(out, "\n  ", "The database is empty.")
+	fmt.Fprint(out, "\n  ", "You can try updating the database using ")
+	p.print("tldr --update", p.theme.Verbatim)
+	fmt.Fprint(out, ".\n\n")
+}
+
+// PageUnavailable tells the user the page for a command is not in the
+// database and which pages they might have meant instead
+func PageUnavailable(out io.Writer, command string, suggestions Suggestions, options PrettyPrintOptions) {
+	p := newPrettyPrinter(out, options)
 
-		case '>':
-			fmt.Print("  ")
-			processLine(line[1:], note)
+	fmt.Fprint(out, "\n  ")
+	p.print(command, p.theme.Heading)
+	fmt.Fprint(out, " documentation is not available.")
 
-		case '-':
-			fmt.Print("\n- ")
-			processLine(line[1:], description)
+	if len(suggestions.Names) > 0 {
+		fmt.Fprint(out, "\n  ", "Did you mean ")
+
+		for i, name := range suggestions.Names {
+			if i > 0 {
+				fmt.Fprint(out, ", ")
+			}
 
-		default:
-			fmt.Print("  ")
-			processLine(line, normal)
+			p.print(name, p.theme.Verbatim)
 		}
+
+		fmt.Fprint(out, "?")
 	}
 
-	// Add an extra blank line at the end of the page
-	fmt.Println()
+	if len(suggestions.Platforms) > 0 {
+		fmt.Fprint(out, "\n  ", "It is available for ", strings.Join(suggestions.Platforms, ", "), ", try ")
+		p.print("tldr -p "+suggestions.Platforms[0]+" "+command, p.theme.Verbatim)
+		fmt.Fprint(out, ".")
+	}
+	fmt.Fprint(out, "\n  ", "You can try updating the database using ")
+	p.print("tldr --update", p.theme.Verbatim)
+	fmt.Fprint(out, ".")
+	fmt.Fprint(out, "\n  ", "Or add a page yourself to https://github.com/tldr-pages/tldr.", "\n\n")
 }
 
-func processLine(line string, defaultStyle color) {
-	// Remove unneeded spaces
-	line = strings.TrimSpace(line)
+// PrettyPrint writes the page formatted for a terminal, problems in
+// the page are ignored so drafts can be shown as well
+func PrettyPrint(out io.Writer, source []byte, options PrettyPrintOptions) {
+	page, _ := Parse(source)
 
-	// Our parsing method would fail on ``, but as
-	// these a no-ops we can safely remove them.
-	line = strings.Replace(line, "``", "", -1)
+	p := newPrettyPrinter(out, options)
+	p.printPage(page)
+}
 
-	inVerbatim := line[0] == '`'
-	for _, part := range strings.Split(line, "`") {
-		// Skip empty strings
-		if len(part) == 0 {
-			continue
-		}
+func (p *prettyPrinter) printPage(page *Page) {
+	// Add an blank line in front of the page
+	fmt.Fprintln(p.out)
 
-		if inVerbatim {
-			// Verbatim
-			processVerbatim(part)
+	if page.Title != "" {
+		fmt.Fprint(p.out, "  ")
+		p.print(page.Title, p.theme.Heading)
+		fmt.Fprintln(p.out)
+	}
 
-		} else {
-			// Normal text
-			fmt.Print(colorize(part, defaultStyle))
-		}
+	for _, line := range page.Description {
+		p.printWrapped(p.segments(line.Tokens, p.theme.Note), "  ", "  ", "")
+		fmt.Fprintln(p.out)
+	}
+
+	if page.MoreInfo != "" {
+		moreInfo := []segment{{moreInfoPrefix + " <" + page.MoreInfo + ">.", p.theme.Note, false}}
+		p.printWrapped(moreInfo, "  ", "  ", "")
+		fmt.Fprintln(p.out)
+	}
+
+	for _, ex := range page.Examples {
+		fmt.Fprintln(p.out)
+		p.printWrapped(p.segments(ex.Description.Tokens, p.theme.Description), "- ", "  ", "")
+		fmt.Fprintln(p.out)
 
-		inVerbatim = !inVerbatim
+		before, after := ex.splitNotes()
+		p.printNotes(before)
+
+		// Long commands are continued like in a shell
+		p.printWrapped(p.segments(ex.Command.Tokens, p.theme.Verbatim), "  ", "      ", " \\")
+		fmt.Fprintln(p.out)
+
+		p.printNotes(after)
 	}
 
-	// As you might have noticed, we never check if the backticks are balanced.
-	// But that check is not regular, and pages should be valid,
-	// so in theory we never have a case where the backticks aren't balanced.
+	// Add an extra blank line at the end of the page
+	fmt.Fprintln(p.out)
+}
 
-	// Go to the next line
-	fmt.Println()
+// printNotes prints the notes of an example
+func (p *prettyPrinter) printNotes(notes []Line) {
+	for _, note := range notes {
+		p.printWrapped(p.segments(note.Tokens, p.theme.Description), "  ", "  ", "")
+		fmt.Fprintln(p.out)
+	}
 }
 
-func processVerbatim(line string) {
-	// Our parsing method would fail on {{}} or }}{{, but as
-	// these a no-ops we can safely remove them.
-	line = strings.Replace(line, "{{}}", "", -1)
-	line = strings.Replace(line, "}}{{", "", -1)
-
-	inExample := strings.HasPrefix(line, "{{")
-	for _, segment := range strings.Split(line, "{{") {
-		for _, part := range strings.Split(segment, "}}") {
-			if inExample {
-				// Optional
-				fmt.Print(colorize(part, example))
-
-			} else {
-				// Verbatim
-				fmt.Print(colorize(part, verbatim))
-			}
+// segments gives the tokens in the right style, text gets the given style
+func (p *prettyPrinter) segments(tokens []Token, textStyle Style) []segment {
+	segments := make([]segment, len(tokens))
+
+	for i, token := range tokens {
+		switch token.Kind {
+		case TextToken:
+			segments[i] = segment{token.Text, textStyle, false}
+
+		case CodeToken:
+			segments[i] = segment{token.Text, p.theme.Verbatim, false}
 
-			inExample = !inExample
+		case PlaceholderToken:
+			// Placeholders are kept together
+			segments[i] = segment{token.Text, p.theme.Example, true}
 		}
 	}
 
-	// As you might have noticed, we never check if the braces are balanced.
-	// But that check is not regular, and pages should be valid,
-	// so in theory we never have a case where the braces aren't balanced.
+	return segments
 }
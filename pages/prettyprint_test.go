@@ -1,4 +1,4 @@
-// Copyright © 2019 Evert Provoost
+// Copyright © 2020 Evert Provoost
 //
 // This program is free software: you can redistribute it and/or modify
 // it under the terms of the GNU General Public License as published by
@@ -16,27 +16,19 @@
 package pages
 
 import (
-	"fmt"
-	"io/ioutil"
-	"os"
+	"bytes"
+	"testing"
 )
 
-// Render shows a rendered view of a page
-func Render(path string) {
-	file, err := os.Open(path)
+func TestPrettyPrintDraft(t *testing.T) {
+	// Lines which aren't part of the syntax are shown where they are
+	source := "# x\n\nsome paragraph text\n\n- Do it:\n\nbetween text\n\n`x`\n\nafter text\n"
+	want := "\n  x\n  some paragraph text\n\n- Do it:\n  between text\n  x\n  after text\n\n"
 
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "error:", err)
-		os.Exit(1)
-	}
-
-	page, err := ioutil.ReadAll(file)
+	var out bytes.Buffer
+	PrettyPrint(&out, []byte(source), PrettyPrintOptions{})
 
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "error:", err)
-		os.Exit(1)
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
 	}
-
-	// Now print the page
-	prettyPrint(page)
 }
@@ -0,0 +1,169 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// SchemaVersion is the version of the database layout written by this
+// version of tldr:
+//
+//  1. a pages bucket for english and a bucket for every other language, each
+//     containing a bucket per platform, the pages are stored as is
+//  2. a metadata bucket is added and pages are compressed
+const SchemaVersion = 2
+
+// migrations[i] upgrades a database from version i+1 to i+2
+var migrations = []func(tx *bbolt.Tx) error{
+	compressPages,
+}
+
+// SchemaError is returned when a database was written by a newer version
+// of tldr, which this version can't read
+type SchemaError struct {
+	// Version is the version of the database
+	Version int
+}
+
+func (err *SchemaError) Error() string {
+	return fmt.Sprintf("database format version %d is newer than the supported version %d",
+		err.Version, SchemaVersion)
+}
+
+// schemaVersion returns the version of the database with the given metadata,
+// databases from before versioning have version 1
+func schemaVersion(meta metadata) int {
+	version, err := strconv.Atoi(meta[metaSchema])
+
+	if err != nil {
+		return 1
+	}
+
+	return version
+}
+
+// Migrate upgrades the database at the given path to the current schema
+// version, the version it had before is returned. If the database is newer
+// than this version of tldr a *SchemaError is returned.
+func Migrate(dbPath string) (int, error) {
+	meta, err := loadMetadata(dbPath)
+
+	if err != nil {
+		return 0, err
+	}
+
+	version := schemaVersion(meta)
+
+	if version > SchemaVersion {
+		return version, &SchemaError{version}
+	}
+
+	// Already up to date?
+	if version == SchemaVersion {
+		return version, nil
+	}
+
+	database, err := bbolt.Open(dbPath, 0600,
+		&bbolt.Options{
+			Timeout: 1 * time.Second,
+		})
+
+	if err != nil {
+		return version, err
+	}
+
+	defer database.Close()
+
+	// Migrate in a single transaction, so we never end up half way
+	err = database.Update(
+		func(tx *bbolt.Tx) error {
+			for _, migrate := range migrations[version-1:] {
+				err := migrate(tx)
+
+				if err != nil {
+					return err
+				}
+			}
+
+			return writeMetadata(tx, metadata{metaSchema: strconv.Itoa(SchemaVersion)})
+		})
+
+	return version, err
+}
+
+// compressPages compresses all pages which are stored as is
+func compressPages(tx *bbolt.Tx) error {
+	// Are they compressed already?
+	if readMetadata(tx)[metaCodec] != codecRaw {
+		return nil
+	}
+
+	err := tx.ForEach(
+		func(name []byte, language *bbolt.Bucket) error {
+			if bytes.Equal(name, metaBucket) {
+				return nil
+			}
+
+			// Every platform is a bucket in the language
+			return language.ForEach(
+				func(platform, value []byte) error {
+					if value != nil {
+						return nil
+					}
+
+					return compressBucket(language.Bucket(platform))
+				})
+		})
+
+	if err != nil {
+		return err
+	}
+
+	return writeMetadata(tx, metadata{metaCodec: codecDeflate})
+}
+
+// compressBucket compresses all pages in the bucket
+func compressBucket(bucket *bbolt.Bucket) error {
+	compressed := make(map[string][]byte)
+
+	// A bucket can't be modified while iterating over it
+	err := bucket.ForEach(
+		func(name, page []byte) error {
+			encoded, err := encodePage(page)
+			compressed[string(name)] = encoded
+			return err
+		})
+
+	if err != nil {
+		return err
+	}
+
+	for name, page := range compressed {
+		err = bucket.Put([]byte(name), page)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
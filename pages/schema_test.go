@@ -0,0 +1,132 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"go.etcd.io/bbolt"
+)
+
+// writeDatabase creates a database at the given path, filled by fill
+func writeDatabase(t *testing.T, dbPath string, fill func(tx *bbolt.Tx) error) {
+	t.Helper()
+
+	database, err := bbolt.Open(dbPath, 0600, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer database.Close()
+
+	err = database.Update(fill)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateVersion1(t *testing.T) {
+	dbPath := filepath.Join(tempDir(t), "pages.db")
+	page := []byte("# ls\n\n> List files.\n\n- List:\n\n`ls`\n")
+
+	// Version 1 databases have no metadata and store the pages as is
+	writeDatabase(t, dbPath,
+		func(tx *bbolt.Tx) error {
+			english, err := tx.CreateBucket(defaultBucket)
+
+			if err != nil {
+				return err
+			}
+
+			common, err := english.CreateBucket(commonBucket)
+
+			if err != nil {
+				return err
+			}
+
+			return common.Put([]byte("ls"), page)
+		})
+
+	version, err := Migrate(dbPath)
+
+	if err != nil || version != 1 {
+		t.Fatalf("Migrate() = %d, %v, want 1", version, err)
+	}
+
+	database, err := bbolt.Open(dbPath, 0600, &bbolt.Options{ReadOnly: true})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer database.Close()
+
+	database.View(
+		func(tx *bbolt.Tx) error {
+			meta := readMetadata(tx)
+
+			if meta[metaSchema] != strconv.Itoa(SchemaVersion) || meta[metaCodec] != codecDeflate {
+				t.Errorf("metadata after migrating is %v", meta)
+			}
+
+			stored := tx.Bucket(defaultBucket).Bucket(commonBucket).Get([]byte("ls"))
+			decoded, err := decodePage(meta[metaCodec], stored)
+
+			if bytes.Equal(stored, page) || err != nil || !bytes.Equal(decoded, page) {
+				t.Errorf("page is stored as %q after migrating, which decodes to %q, %v", stored, decoded, err)
+			}
+
+			return nil
+		})
+}
+
+func TestMigrateCurrent(t *testing.T) {
+	dbPath := filepath.Join(tempDir(t), "pages.db")
+
+	writeDatabase(t, dbPath,
+		func(tx *bbolt.Tx) error {
+			return writeMetadata(tx, metadata{metaSchema: strconv.Itoa(SchemaVersion), metaCodec: codecDeflate})
+		})
+
+	version, err := Migrate(dbPath)
+
+	if err != nil || version != SchemaVersion {
+		t.Errorf("Migrate() = %d, %v, want %d", version, err, SchemaVersion)
+	}
+}
+
+func TestMigrateNewer(t *testing.T) {
+	dbPath := filepath.Join(tempDir(t), "pages.db")
+
+	writeDatabase(t, dbPath,
+		func(tx *bbolt.Tx) error {
+			return writeMetadata(tx, metadata{metaSchema: strconv.Itoa(SchemaVersion + 1)})
+		})
+
+	_, err := Migrate(dbPath)
+
+	var schemaErr *SchemaError
+
+	if !errors.As(err, &schemaErr) || schemaErr.Version != SchemaVersion+1 {
+		t.Errorf("Migrate() of a newer database returned %v", err)
+	}
+}
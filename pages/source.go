@@ -0,0 +1,325 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// DefaultSources are the locations from where we download the pages by
+// default, in the order in which they are tried.
+var DefaultSources = []string{
+	"https://tldr.sh/assets/tldr.zip",
+	"https://github.com/tldr-pages/tldr/releases/latest/download/tldr.zip",
+}
+
+// source is a location containing the tldr pages, laid out as in the
+// tldr-pages repository: a pages directory for english and one pages.<lang>
+// directory for every translation.
+type source interface {
+	// files returns all files in the source and the metadata to store
+	// alongside them, the metadata of the current database is passed so
+	// errNotModified can be returned if it is still up to date
+	files(cached metadata, options UpdateOptions) ([]sourceFile, metadata, error)
+}
+
+// maxFetchSize is the maximum size of checksum and signature files
+const maxFetchSize = 1 << 20
+
+// errNotModified is returned by a source if the pages didn't change since
+// they were last fetched
+var errNotModified = errors.New("pages not modified")
+
+// sourceFile is a single file in a source
+type sourceFile struct {
+	// name is the slash separated path of the file relative to the source
+	name string
+
+	// open opens the file for reading
+	open func() (io.ReadCloser, error)
+}
+
+// httpSource is a zip archive which has to be downloaded
+type httpSource string
+
+// zipSource is a zip archive on the local file system
+type zipSource string
+
+// dirSource is a directory on the local file system
+type dirSource string
+
+// openSource returns the source at the given location, which is either an
+// HTTP(S) URL to a zip archive or a file:// URL or path pointing to a zip
+// archive or a directory.
+func openSource(location string) (source, error) {
+	// Is it an URL?
+	u, err := url.Parse(location)
+
+	if err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return httpSource(location), nil
+	}
+
+	// A local file then
+	return openLocalSource(location)
+}
+
+// openLocalSource returns the zip archive or directory at the given
+// file:// URL or path.
+func openLocalSource(location string) (source, error) {
+	path := localPath(location)
+
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return dirSource(path), nil
+	}
+
+	return zipSource(path), nil
+}
+
+// localPath converts a file:// URL to a path, other locations are
+// assumed to be paths already
+func localPath(location string) string {
+	u, err := url.Parse(location)
+
+	if err == nil && u.Scheme == "file" {
+		return filepath.FromSlash(u.Path)
+	}
+
+	return location
+}
+
+// readAtMost reads everything from the reader, failing if that is more than max bytes
+func readAtMost(reader io.Reader, max int64) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(reader, max+1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > max {
+		return nil, fmt.Errorf("larger than %d bytes", max)
+	}
+
+	return data, nil
+}
+
+// readFileAtMost reads the file at the given path, failing if it is larger than max bytes
+func readFileAtMost(path string, max int64) ([]byte, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	data, err := readAtMost(file, max)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return data, nil
+}
+
+// fetch reads the small file at the given HTTP(S) URL, file:// URL or path
+func fetch(location string, options UpdateOptions) ([]byte, error) {
+	u, err := url.Parse(location)
+
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return readFileAtMost(localPath(location), maxFetchSize)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, location, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, data, err := download(req, maxFetchSize, options)
+	return data, err
+}
+
+func (src httpSource) files(cached metadata, options UpdateOptions) ([]sourceFile, metadata, error) {
+	req, err := http.NewRequest(http.MethodGet, string(src), nil)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Only download the archive if it changed since we last fetched it
+	if cached[metaSource] == string(src) {
+		if etag := cached[metaETag]; etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+
+		if modified := cached[metaLastModified]; modified != "" {
+			req.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	// Download the ZIP file
+	resp, zipFile, err := download(req, options.MaxDownloadSize, options)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, nil, errNotModified
+	}
+
+	// Make sure we got what we expected
+	err = verify(zipFile, path.Base(req.URL.Path), options)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, err := zipFiles(zipFile, options.MaxEntries)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return files, metadata{
+		metaSource:       string(src),
+		metaETag:         resp.Header.Get("ETag"),
+		metaLastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+func (src zipSource) files(cached metadata, options UpdateOptions) ([]sourceFile, metadata, error) {
+	zipFile, err := readFileAtMost(string(src), options.MaxDownloadSize)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Make sure we got what we expected
+	err = verify(zipFile, filepath.Base(string(src)), options)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, err := zipFiles(zipFile, options.MaxEntries)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return files, metadata{metaSource: string(src)}, nil
+}
+
+func (src dirSource) files(cached metadata, options UpdateOptions) ([]sourceFile, metadata, error) {
+	// Checksums and signatures only apply to archives
+	if options.Checksum != "" || options.Signature != "" || options.PublicKey != "" {
+		return nil, nil, errors.New("cannot verify directory " + string(src) + ", use an archive instead")
+	}
+
+	var files []sourceFile
+
+	// Collect all regular files in the directory
+	err := filepath.Walk(string(src),
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil || !info.Mode().IsRegular() {
+				return err
+			}
+
+			if len(files) >= options.MaxEntries {
+				return fmt.Errorf("%s: more than %d files", src, options.MaxEntries)
+			}
+
+			name, err := filepath.Rel(string(src), path)
+
+			if err != nil {
+				return err
+			}
+
+			files = append(files, sourceFile{
+				name: filepath.ToSlash(name),
+				open: func() (io.ReadCloser, error) { return os.Open(path) },
+			})
+
+			return nil
+		})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return files, metadata{metaSource: string(src)}, nil
+}
+
+// zipFiles lists the files in a zip archive, failing if there are more than maxEntries
+func zipFiles(zipFile []byte, maxEntries int) ([]sourceFile, error) {
+	// Turn this array into a zip reader
+	zipReader, err := zip.NewReader(
+		bytes.NewReader(zipFile),
+		int64(len(zipFile)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(zipReader.File) > maxEntries {
+		return nil, fmt.Errorf("archive contains more than %d files", maxEntries)
+	}
+
+	files := make([]sourceFile, 0, len(zipReader.File))
+
+	for _, file := range zipReader.File {
+		mode := file.Mode()
+
+		// Directories only matter through the files they contain
+		if mode.IsDir() {
+			continue
+		}
+
+		open := file.Open
+
+		// Refuse to follow symbolic links and the like
+		if !mode.IsRegular() {
+			open = func() (io.ReadCloser, error) {
+				return nil, errors.New("not a regular file")
+			}
+		}
+
+		files = append(files, sourceFile{
+			name: file.Name,
+			open: open,
+		})
+	}
+
+	return files, nil
+}
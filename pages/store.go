@@ -0,0 +1,74 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+// English is the language code of the english pages, which are
+// complete, other languages only contain translations
+const English = "en"
+
+// Common is the platform of the pages which apply to all platforms
+const Common = "common"
+
+// PageStore gives access to a collection of pages, organised by language
+// and platform
+type PageStore interface {
+	// Page returns the page with the given name for the language and
+	// platform, or nil if there is no such page
+	Page(language, platform, name string) ([]byte, error)
+
+	// Names returns the names of all pages for the language and platform
+	// in alphabetical order
+	Names(language, platform string) ([]string, error)
+
+	// Platforms returns all platforms with english pages in alphabetical
+	// order, apart from Common
+	Platforms() ([]string, error)
+
+	// Languages returns all languages with pages in alphabetical order,
+	// this is empty if the store is empty
+	Languages() ([]string, error)
+
+	// Metadata returns the value of the given metadata key, or an empty
+	// string if it isn't set
+	Metadata(key string) (string, error)
+}
+
+// isEmpty checks if there are any pages in the store
+func isEmpty(store PageStore) (bool, error) {
+	languages, err := store.Languages()
+	return len(languages) == 0, err
+}
+
+// hasPlatform checks if the store contains pages for the platform
+func hasPlatform(store PageStore, platform string) (bool, error) {
+	if platform == Common {
+		return true, nil
+	}
+
+	platforms, err := store.Platforms()
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range platforms {
+		if p == platform {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
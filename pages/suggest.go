@@ -0,0 +1,198 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSuggestions is the maximum number of names Suggest returns
+const maxSuggestions = 5
+
+// Suggestions are alternatives for a command without a page
+type Suggestions struct {
+	// Names are the names of similar pages, most similar first
+	Names []string
+
+	// Platforms are the other platforms which have a page for the command
+	Platforms []string
+}
+
+// Suggest looks for pages similar to the command, for when Lookup
+// couldn't find it. Names are similar if they are a few typos away from
+// the command or start with it, pages for the platform of the client are
+// preferred over those for other platforms.
+func (client *Client) Suggest(command ...string) (Suggestions, error) {
+	name := strings.Join(command, "-")
+
+	err := client.checkEmpty()
+
+	if err != nil {
+		return Suggestions{}, err
+	}
+
+	platforms, err := client.store.Platforms()
+
+	if err != nil {
+		return Suggestions{}, err
+	}
+
+	// The platforms Lookup already looked at
+	relevant := make(map[string]bool)
+
+	for _, platform := range client.platforms() {
+		relevant[platform] = true
+	}
+
+	type candidate struct {
+		name     string
+		score    int
+		relevant bool
+	}
+
+	var suggestions Suggestions
+	candidates := make(map[string]*candidate)
+
+	for _, platform := range append([]string{Common}, platforms...) {
+		names, err := client.store.Names(English, platform)
+
+		if err != nil {
+			return Suggestions{}, err
+		}
+
+		for _, other := range names {
+			// The page exists, just not where we looked
+			if other == name {
+				if !relevant[platform] {
+					suggestions.Platforms = append(suggestions.Platforms, platform)
+				}
+
+				continue
+			}
+
+			score, ok := similarity(name, other)
+
+			if !ok {
+				continue
+			}
+
+			if c, ok := candidates[other]; ok {
+				c.relevant = c.relevant || relevant[platform]
+			} else {
+				candidates[other] = &candidate{other, score, relevant[platform]}
+			}
+		}
+	}
+
+	// Most similar first, then those for the platform of the client
+	sorted := make([]*candidate, 0, len(candidates))
+
+	for _, c := range candidates {
+		sorted = append(sorted, c)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+
+		if a.score != b.score {
+			return a.score < b.score
+		}
+
+		if a.relevant != b.relevant {
+			return a.relevant
+		}
+
+		return a.name < b.name
+	})
+
+	for i := 0; i < len(sorted) && i < maxSuggestions; i++ {
+		suggestions.Names = append(suggestions.Names, sorted[i].name)
+	}
+
+	return suggestions, nil
+}
+
+// similarity checks if the name of a page is similar to the command, a lower
+// score means it is more similar. Names which start with the command are
+// considered as similar as those with a single typo.
+func similarity(command, name string) (int, bool) {
+	// Allow one typo for every three characters, up to three
+	maxDistance := utf8.RuneCountInString(command) / 3
+
+	if maxDistance < 1 {
+		maxDistance = 1
+	} else if maxDistance > 3 {
+		maxDistance = 3
+	}
+
+	distance := editDistance(command, name)
+
+	if utf8.RuneCountInString(command) >= 2 && strings.HasPrefix(name, command) && distance > 1 {
+		distance = 1
+	}
+
+	return distance, distance <= maxDistance
+}
+
+// editDistance returns the optimal string alignment distance between a and b,
+// which is the Levenshtein distance where swapping two adjacent characters,
+// a common typo, counts as a single edit as well
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// Only keep the last three rows of the matrix
+	prevPrev := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prevPrev[j-2]+1)
+			}
+		}
+
+		prevPrev, prev, cur = prev, cur, prevPrev
+	}
+
+	return prev[len(rb)]
+}
+
+// min returns the smallest of the numbers
+func min(first int, others ...int) int {
+	for _, n := range others {
+		if n < first {
+			first = n
+		}
+	}
+
+	return first
+}
@@ -0,0 +1,86 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		distance int
+	}{
+		{"", "", 0},
+		{"tar", "", 3},
+		{"", "tar", 3},
+		{"tar", "tar", 0},
+		{"tar", "tat", 1},
+		{"tar", "tra", 1},
+		{"git", "gti", 1},
+		{"ab", "ba", 1},
+		{"abc", "ca", 3},
+		{"tldr", "tdlr", 1},
+		{"kitten", "sitting", 3},
+		{"grep", "egrep", 1},
+		{"café", "cafe", 1},
+	}
+
+	for _, test := range tests {
+		if distance := editDistance(test.a, test.b); distance != test.distance {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", test.a, test.b, distance, test.distance)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	store := NewMemoryStore()
+	store.Add(English, Common, "tar", []byte("# tar"))
+	store.Add(English, Common, "git", []byte("# git"))
+	store.Add(English, Common, "git-commit", []byte("# git commit"))
+	store.Add(English, Common, "git-checkout", []byte("# git checkout"))
+	store.Add(English, "linux", "apt", []byte("# apt"))
+	store.Add(English, "osx", "tac", []byte("# tac"))
+	store.Add(English, "osx", "open", []byte("# open"))
+
+	client := NewClient(store)
+	client.Platform = "linux"
+
+	tests := []struct {
+		command     []string
+		suggestions Suggestions
+	}{
+		// Pages for the platform of the client come first
+		{[]string{"taz"}, Suggestions{Names: []string{"tar", "tac"}}},
+		{[]string{"git", "comit"}, Suggestions{Names: []string{"git-commit"}}},
+		{[]string{"git"}, Suggestions{Names: []string{"git-checkout", "git-commit"}}},
+
+		// Swapped letters are a single typo
+		{[]string{"gti"}, Suggestions{Names: []string{"git"}}},
+		{[]string{"tra"}, Suggestions{Names: []string{"tar"}}},
+		{[]string{"open"}, Suggestions{Platforms: []string{"osx"}}},
+		{[]string{"xyzzy"}, Suggestions{}},
+	}
+
+	for _, test := range tests {
+		suggestions, err := client.Suggest(test.command...)
+
+		if err != nil || !reflect.DeepEqual(suggestions, test.suggestions) {
+			t.Errorf("Suggest(%q) = %+v, %v, want %+v", test.command, suggestions, err, test.suggestions)
+		}
+	}
+}
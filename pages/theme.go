@@ -0,0 +1,215 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Theme contains the styles used to show pages in a terminal
+type Theme struct {
+	// Heading is the style of the title
+	Heading Style
+
+	// Note is the style of the description of the command
+	Note Style
+
+	// Description is the style of the descriptions of the examples
+	Description Style
+
+	// Verbatim is the style of code which has to be typed literally
+	Verbatim Style
+
+	// Example is the style of placeholders
+	Example Style
+}
+
+// DefaultTheme is the theme used if no other is chosen
+var DefaultTheme = Themes["default"]
+
+// Themes are the built-in themes by name
+var Themes = map[string]Theme{
+	"default": {
+		Heading:  Style{Bold: true},
+		Verbatim: Style{Foreground: colBrightRed},
+		Example:  Style{Italic: true},
+	},
+
+	// Only bright colors and no faint or italic text
+	"high-contrast": {
+		Heading:     Style{Foreground: colBrightGray, Bold: true, Underline: true},
+		Note:        Style{Foreground: colBrightGray},
+		Description: Style{Foreground: colBrightGray, Bold: true},
+		Verbatim:    Style{Foreground: colBrightYellow, Bold: true},
+		Example:     Style{Foreground: colBrightCyan, Underline: true},
+	},
+
+	// For terminals without colors or users who prefer none
+	"monochrome": {
+		Heading:  Style{Bold: true, Underline: true},
+		Verbatim: Style{Bold: true},
+		Example:  Style{Underline: true},
+	},
+
+	// Uses the truecolor palette of Solarized
+	"solarized": {
+		Heading:     Style{Foreground: Color{kindRGB, 0x268bd2}, Bold: true},
+		Note:        Style{Foreground: Color{kindRGB, 0x93a1a1}},
+		Description: Style{Foreground: Color{kindRGB, 0x859900}},
+		Verbatim:    Style{Foreground: Color{kindRGB, 0xcb4b16}},
+		Example:     Style{Foreground: Color{kindRGB, 0x2aa198}, Italic: true},
+	},
+}
+
+// styleModifiers are the names of the modifiers of a Style
+var styleModifiers = map[string]func(*Style){
+	"bold":      func(style *Style) { style.Bold = true },
+	"faint":     func(style *Style) { style.Faint = true },
+	"italic":    func(style *Style) { style.Italic = true },
+	"underline": func(style *Style) { style.Underline = true },
+	"inverse":   func(style *Style) { style.Inverse = true },
+}
+
+// ParseTheme parses a theme from a colon separated list of element=style
+// pairs, the elements are heading, note, description, verbatim and example.
+// A style is a comma separated list of modifiers (bold, faint, italic,
+// underline or inverse) and colors, colors are given by name, as a number
+// from 0 to 255 or as #rrggbb and apply to the background if prefixed by
+// on-. The name of a built-in theme can be given as well, the pairs after it
+// change that theme. Elements which aren't mentioned keep the default style.
+//
+// For example: "monochrome:verbatim=bold,208:example=underline,#87afff"
+func ParseTheme(spec string) (*Theme, error) {
+	theme := DefaultTheme
+
+	elements := map[string]*Style{
+		"heading":     &theme.Heading,
+		"note":        &theme.Note,
+		"description": &theme.Description,
+		"verbatim":    &theme.Verbatim,
+		"example":     &theme.Example,
+	}
+
+	for _, part := range strings.Split(spec, ":") {
+		part = strings.TrimSpace(part)
+
+		if part == "" {
+			continue
+		}
+
+		eq := strings.IndexByte(part, '=')
+
+		// Is it a built-in theme?
+		if eq < 0 {
+			preset, ok := Themes[part]
+
+			if !ok {
+				return nil, errors.New("unknown theme '" + part + "', expected one of " + themeNames())
+			}
+
+			theme = preset
+			continue
+		}
+
+		element, ok := elements[strings.TrimSpace(part[:eq])]
+
+		if !ok {
+			return nil, errors.New("unknown element '" + part[:eq] + "' in theme")
+		}
+
+		style, err := parseStyle(part[eq+1:])
+
+		if err != nil {
+			return nil, err
+		}
+
+		*element = style
+	}
+
+	return &theme, nil
+}
+
+// parseStyle parses a comma separated list of modifiers and colors
+func parseStyle(spec string) (Style, error) {
+	var style Style
+
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+
+		if field == "" {
+			continue
+		}
+
+		if modify, ok := styleModifiers[field]; ok {
+			modify(&style)
+			continue
+		}
+
+		// The color of the background?
+		target := &style.Foreground
+
+		if strings.HasPrefix(field, "on-") {
+			target = &style.Background
+			field = strings.TrimPrefix(field, "on-")
+		}
+
+		col, err := parseColor(field)
+
+		if err != nil {
+			return Style{}, err
+		}
+
+		*target = col
+	}
+
+	return style, nil
+}
+
+// parseColor parses a color name, a number from 0 to 255 or #rrggbb
+func parseColor(spec string) (Color, error) {
+	if col, ok := colorNames[spec]; ok {
+		return col, nil
+	}
+
+	if strings.HasPrefix(spec, "#") && len(spec) == 7 {
+		rgb, err := strconv.ParseUint(spec[1:], 16, 32)
+
+		if err == nil {
+			return Color{kindRGB, uint32(rgb)}, nil
+		}
+	}
+
+	if number, err := strconv.ParseUint(spec, 10, 8); err == nil {
+		return Color{kind256, uint32(number)}, nil
+	}
+
+	return Color{}, errors.New("unknown color or modifier '" + spec + "' in theme")
+}
+
+// themeNames lists the names of the built-in themes
+func themeNames() string {
+	names := make([]string, 0, len(Themes))
+
+	for name := range Themes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,82 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import "testing"
+
+func TestParseTheme(t *testing.T) {
+	monochrome := Themes["monochrome"]
+
+	tests := []struct {
+		spec  string
+		theme Theme
+	}{
+		{"", DefaultTheme},
+		{"monochrome", monochrome},
+		{"monochrome:verbatim=bold,208:example=underline,#87afff", Theme{
+			Heading:  monochrome.Heading,
+			Verbatim: Style{Foreground: Color{kind256, 208}, Bold: true},
+			Example:  Style{Foreground: Color{kindRGB, 0x87afff}, Underline: true},
+		}},
+		{"heading=on-blue,bright-white : note=faint,ITALIC", Theme{
+			Heading:  Style{Foreground: colBrightGray, Background: colBlue},
+			Note:     Style{Faint: true, Italic: true},
+			Verbatim: DefaultTheme.Verbatim,
+			Example:  DefaultTheme.Example,
+		}},
+		{"description=0,on-255,inverse", Theme{
+			Heading:     DefaultTheme.Heading,
+			Description: Style{Foreground: Color{kind256, 0}, Background: Color{kind256, 255}, Inverse: true},
+			Verbatim:    DefaultTheme.Verbatim,
+			Example:     DefaultTheme.Example,
+		}},
+		{"verbatim=on-#000000,default", Theme{
+			Heading:  DefaultTheme.Heading,
+			Verbatim: Style{Background: Color{kindRGB, 0}},
+			Example:  DefaultTheme.Example,
+		}},
+	}
+
+	for _, test := range tests {
+		theme, err := ParseTheme(test.spec)
+
+		if err != nil {
+			t.Errorf("ParseTheme(%q) failed: %v", test.spec, err)
+		} else if *theme != test.theme {
+			t.Errorf("ParseTheme(%q) = %+v, want %+v", test.spec, *theme, test.theme)
+		}
+	}
+}
+
+func TestParseThemeErrors(t *testing.T) {
+	invalid := []string{
+		"unknown",
+		"title=bold",
+		"verbatim=blink",
+		"verbatim=256",
+		"verbatim=-1",
+		"verbatim=#fff",
+		"verbatim=#gggggg",
+		"verbatim=on-",
+		"verbatim=on-bold",
+	}
+
+	for _, spec := range invalid {
+		if _, err := ParseTheme(spec); err == nil {
+			t.Errorf("ParseTheme(%q) succeeded", spec)
+		}
+	}
+}
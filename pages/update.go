@@ -16,170 +16,498 @@
 package pages
 
 import (
-	"archive/zip"
-	"bytes"
 	"errors"
-	"fmt"
+	"io"
 	"io/ioutil"
-	"net/http"
 	"os"
 	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"go.etcd.io/bbolt"
 )
 
-// Update fetches all pages and stores them in the database
-func Update(database *bbolt.DB) {
-	// Download the ZIP file
-	zipReader, err := downloadZip(pagesSource)
+// UpdateOptions configures how the pages are fetched
+type UpdateOptions struct {
+	// Checksum is the location of a file listing the SHA-256 checksum
+	// of the archive in the format used by sha256sum
+	Checksum string
+
+	// Signature is the location of a detached ed25519 signature
+	// of the archive, either raw or hex or base64 encoded
+	Signature string
+
+	// PublicKey is the hex or base64 encoded ed25519 public key
+	// used to check the signature
+	PublicKey string
+
+	// MaxDownloadSize is the maximum size of the archive in bytes,
+	// zero means the default is used
+	MaxDownloadSize int64
+
+	// MaxPageSize is the maximum uncompressed size of a single page
+	// in bytes, larger pages are skipped, zero means the default is used
+	MaxPageSize int64
+
+	// MaxEntries is the maximum number of files in the source,
+	// zero means the default is used
+	MaxEntries int
+
+	// ConnectTimeout limits how long connecting to a server may take,
+	// zero means the default is used
+	ConnectTimeout time.Duration
+
+	// Timeout limits how long a single download may take in total,
+	// zero means the default is used
+	Timeout time.Duration
+
+	// Retries is the number of times a failed download is retried,
+	// zero means the default is used and a negative number disables retries
+	Retries int
+
+	// Progress is where download progress is shown, nil to stay quiet
+	Progress io.Writer
+}
+
+// Defaults for the limits in UpdateOptions, these are far above what the
+// official pages need
+const (
+	defaultMaxDownloadSize = 128 << 20
+	defaultMaxPageSize     = 256 << 10
+	defaultMaxEntries      = 100000
+)
+
+// withDefaults fills in the default for every setting which isn't set
+func (options UpdateOptions) withDefaults() UpdateOptions {
+	if options.MaxDownloadSize <= 0 {
+		options.MaxDownloadSize = defaultMaxDownloadSize
+	}
+
+	if options.MaxPageSize <= 0 {
+		options.MaxPageSize = defaultMaxPageSize
+	}
+
+	if options.MaxEntries <= 0 {
+		options.MaxEntries = defaultMaxEntries
+	}
+
+	if options.ConnectTimeout <= 0 {
+		options.ConnectTimeout = defaultConnectTimeout
+	}
+
+	if options.Timeout <= 0 {
+		options.Timeout = defaultTimeout
+	}
+
+	if options.Retries == 0 {
+		options.Retries = defaultRetries
+	}
+
+	return options
+}
+
+// SkippedFile is a file in the source which was not added to the database
+type SkippedFile struct {
+	// Name is the path of the file in the source
+	Name string
+
+	// Reason describes why the file was skipped
+	Reason string
+}
+
+// Update fetches all pages from the first of the given sources that works
+// and replaces the database at the given path with a new one containing them,
+// the files which were left out are returned
+func Update(dbPath string, locations []string, options UpdateOptions) ([]SkippedFile, error) {
+	options = options.withDefaults()
+
+	if len(locations) == 0 {
+		return nil, errors.New("no sources to fetch the pages from")
+	}
+
+	// Try the sources in order
+	var failures []string
+
+	for _, location := range locations {
+		// Find out what kind of source we are dealing with
+		src, err := openSource(location)
+
+		if err == nil {
+			var skipped []SkippedFile
+			skipped, err = rebuild(dbPath, src, options)
+
+			if err == nil {
+				return skipped, nil
+			}
+		}
+
+		// Don't bother repeating a single failure
+		if len(locations) == 1 {
+			recordCheck(dbPath)
+			return nil, err
+		}
+
+		failures = append(failures, "\n  "+location+": "+err.Error())
+	}
+
+	recordCheck(dbPath)
+	return nil, errors.New("all sources failed:" + strings.Join(failures, ""))
+}
+
+// Import replaces the database at the given path with a new one containing
+// all pages from a local zip archive or directory, the files which
+// were left out are returned
+func Import(dbPath, path string, options UpdateOptions) ([]SkippedFile, error) {
+	// Only accept sources which don't need a network connection
+	src, err := openLocalSource(path)
 
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "error:", err)
-		os.Exit(1)
+		return nil, err
 	}
 
-	// Remove all buckets from the old database
-	err = database.Update(
-		func(tx *bbolt.Tx) error {
-			return tx.ForEach(func(bucketName []byte, _ *bbolt.Bucket) error {
-				return tx.DeleteBucket(bucketName)
-			})
+	return rebuild(dbPath, src, options.withDefaults())
+}
+
+// recordCheck remembers that an update of the database at the given path
+// was attempted, even though it failed
+func recordCheck(dbPath string) {
+	// Opening the database would create it
+	if _, err := os.Stat(dbPath); err != nil {
+		return
+	}
+
+	// This is only informative, so failing isn't a problem
+	storeMetadata(dbPath, metadata{metaChecked: now()})
+}
+
+// UpdatedAt returns when the database at the given path was last updated,
+// this is the zero time if that is unknown
+func UpdatedAt(dbPath string) (time.Time, error) {
+	return metadataTime(dbPath, metaUpdated)
+}
+
+// CheckedAt returns when an update of the database at the given path was
+// last attempted, whether it succeeded or not, this is the zero time if
+// that is unknown
+func CheckedAt(dbPath string) (time.Time, error) {
+	return metadataTime(dbPath, metaChecked)
+}
+
+// UpdatedFrom returns the location the pages in the database at the given
+// path were fetched from, this is empty if that is unknown
+func UpdatedFrom(dbPath string) (string, error) {
+	meta, err := loadMetadata(dbPath)
+
+	if err != nil {
+		return "", err
+	}
+
+	return meta[metaSource], nil
+}
+
+// metadataTime reads a time from the metadata of the database at the given path
+func metadataTime(dbPath, key string) (time.Time, error) {
+	meta, err := loadMetadata(dbPath)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Older databases don't keep track of this
+	value, err := time.Parse(time.RFC3339, meta[key])
+
+	if err != nil {
+		return time.Time{}, nil
+	}
+
+	return value, nil
+}
+
+// rebuild builds a new database from the source in a temporary file and only
+// replaces the database at the given path once that has fully succeeded, so
+// the old database remains usable if anything goes wrong, including a failed
+// verification of the source.
+func rebuild(dbPath string, src source, options UpdateOptions) ([]SkippedFile, error) {
+	// Find out what the current database contains, one we can't
+	// read is replaced all the same
+	cached, err := loadMetadata(dbPath)
+
+	if err != nil {
+		cached = make(metadata)
+	}
+
+	// A database we can't read has to be rebuilt even if
+	// the pages didn't change, so forget where they came from
+	if schemaVersion(cached) != SchemaVersion {
+		cached = make(metadata)
+	}
+
+	// Fetch the list of files
+	files, meta, err := src.files(cached, options)
+
+	if err == errNotModified {
+		// Nothing to rebuild, just remember that we checked
+		return nil, storeMetadata(dbPath, metadata{metaUpdated: now(), metaChecked: now()})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	meta[metaUpdated] = now()
+	meta[metaChecked] = now()
+	meta[metaCodec] = codecDeflate
+	meta[metaSchema] = strconv.Itoa(SchemaVersion)
+
+	// Create the temporary file next to the database, renaming is only
+	// atomic if both are on the same file system
+	tmp, err := ioutil.TempFile(filepath.Dir(dbPath), filepath.Base(dbPath)+".*.tmp")
+
+	if err != nil {
+		return nil, err
+	}
+
+	tmpPath := tmp.Name()
+	tmp.Close()
+
+	// Clean up if we don't make it to the end
+	defer os.Remove(tmpPath)
+
+	// A pages size of 128 seems to result in the smallest
+	// database size for our purposes, setting it so low
+	// would result in a major slowdown in large databases
+	// but ours is small.
+	database, err := bbolt.Open(tmpPath, 0600,
+		&bbolt.Options{
+			Timeout:  1 * time.Second,
+			PageSize: 128,
 		})
 
-	// Did something go wrong?
 	if err != nil {
-		fmt.Fprintln(os.Stdout, "error:", err)
-		os.Exit(1)
+		return nil, err
+	}
+
+	skipped, err := fill(database, files, meta, options.MaxPageSize)
+
+	// Make sure everything is on disk before we swap the databases
+	if closeErr := database.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return nil, err
 	}
 
-	// Now add the files relevant to this platform to the database
-	err = database.Update(
+	return skipped, os.Rename(tmpPath, dbPath)
+}
+
+// fill adds the pages in the given files and their metadata to an empty
+// database, pages which are larger than maxPageSize bytes, have a suspicious
+// path or can't be read are skipped
+func fill(database *bbolt.DB, files []sourceFile, meta metadata, maxPageSize int64) ([]SkippedFile, error) {
+	var skipped []SkippedFile
+
+	err := database.Update(
 		func(tx *bbolt.Tx) error {
+			// Describe where the pages came from
+			err := writeMetadata(tx, meta)
+
+			if err != nil {
+				return err
+			}
+
 			// Create a new default pages bucket
-			deflt, _ := tx.CreateBucket(defaultBucket)
+			deflt, err := tx.CreateBucket(defaultBucket)
 
-			if deflt == nil {
-				return errors.New("failed to remove old database")
+			if err != nil {
+				return err
+			}
+
+			common, err := deflt.CreateBucket(commonBucket)
+
+			if err != nil {
+				return err
 			}
 
 			// Keep mapping from language to bucket
 			langBucket := make(map[string]*bbolt.Bucket)
-			langBucket["default"] = deflt
+			langBucket[English] = deflt
 
 			// Add list of target buckets per language
 			pageBucket := make(map[string]map[string]*bbolt.Bucket)
-			pageBucket["default"] = make(map[string]*bbolt.Bucket)
-			pageBucket["default"]["common"], _ = deflt.CreateBucket(commonBucket)
+			pageBucket[English] = map[string]*bbolt.Bucket{Common: common}
 
 			// Read in all pages
-			for _, file := range zipReader.File {
-				// Is it a markdown file?
-				if !strings.HasSuffix(path.Base(file.Name), ".md") {
-					// No
+			for _, file := range files {
+				// Don't trust paths which try to escape the source
+				if reason := suspiciousPath(file.name); reason != "" {
+					skipped = append(skipped, SkippedFile{file.name, reason})
 					continue
 				}
 
-				// It's a Markdown file, let us put it in the right spot
-				command := strings.TrimSuffix(path.Base(file.Name), ".md")
-				dir := path.Dir(file.Name)
-
-				var language, target string
-
-				// Detect language
-				// Is it the default: English?
-				if strings.HasPrefix(dir, "pages/") {
-					language = "default"
-					target = strings.TrimPrefix(dir, "pages/")
-
-				} else if strings.HasPrefix(dir, "pages.") {
-					// Else extract the language code and target
-					split := strings.SplitN(dir, "/", 2)
-					language = strings.TrimPrefix(split[0], "pages.")
-					target = split[1]
-
-					// See if the language aleady exists?
-					if langBucket[language] == nil {
-						// No we need to create it
-						nw, _ := tx.CreateBucket([]byte(language))
-						langBucket[language] = nw
-
-						// Also add a common bucket
-						pageBucket[language] = make(map[string]*bbolt.Bucket)
-						pageBucket[language]["common"], _ = nw.CreateBucket(commonBucket)
-					}
+				// Is it a page and where does it belong?
+				language, target, command, ok := locatePage(file.name)
 
-				} else {
+				if !ok {
 					// Not a page to add
 					continue
 				}
 
+				// Make sure it can't end up in the wrong place
+				if reason := suspiciousLocation(language, target, command); reason != "" {
+					skipped = append(skipped, SkippedFile{file.name, reason})
+					continue
+				}
+
 				// Read the page
-				contents, err := file.Open()
+				contents, err := file.open()
 
 				if err != nil {
-					fmt.Println("warning:", err)
+					skipped = append(skipped, SkippedFile{file.name, err.Error()})
 					continue
 				}
 
-				out, err := ioutil.ReadAll(contents)
+				out, err := readAtMost(contents, maxPageSize)
 				contents.Close()
 
 				if err != nil {
-					fmt.Println("warning:", err)
+					skipped = append(skipped, SkippedFile{file.name, err.Error()})
 					continue
 				}
 
+				// Compress the page
+				out, err = encodePage(out)
+
+				if err != nil {
+					return err
+				}
+
+				// See if the language aleady exists?
+				if langBucket[language] == nil {
+					// No we need to create it
+					nw, err := tx.CreateBucket([]byte(language))
+
+					if err != nil {
+						skipped = append(skipped, SkippedFile{file.name, err.Error()})
+						continue
+					}
+
+					// Also add a common bucket
+					common, err := nw.CreateBucket(commonBucket)
+
+					if err != nil {
+						return err
+					}
+
+					langBucket[language] = nw
+					pageBucket[language] = map[string]*bbolt.Bucket{Common: common}
+				}
+
 				// Do we have to create a new bucket?
 				tgtBucket, ok := pageBucket[language][target]
 
 				if !ok {
-					tgtBucket, _ = langBucket[language].CreateBucket([]byte(target))
+					tgtBucket, err = langBucket[language].CreateBucket([]byte(target))
+
+					if err != nil {
+						skipped = append(skipped, SkippedFile{file.name, err.Error()})
+						continue
+					}
+
 					pageBucket[language][target] = tgtBucket
 				}
 
-				// Compress the page and write it to the bucket
-				tgtBucket.Put([]byte(command), out)
+				// Write the page to the bucket
+				err = tgtBucket.Put([]byte(command), out)
+
+				if err != nil {
+					skipped = append(skipped, SkippedFile{file.name, err.Error()})
+				}
 			}
 
 			// Done!
 			return nil
 		})
 
-	// Has something gone wrong?
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "error:", err)
-		os.Exit(1)
+	return skipped, err
+}
+
+// suspiciousPath returns why the path of a file in a source can't be
+// trusted, or an empty string if there is nothing wrong with it
+func suspiciousPath(name string) string {
+	switch {
+	case strings.HasPrefix(name, "/"):
+		return "absolute path"
+
+	case strings.Contains(name, "\\"):
+		return "backslash in path"
+
+	case strings.IndexFunc(name, unicode.IsControl) >= 0:
+		return "control character in path"
+
+	case path.Clean(name) != name, name == "..", strings.HasPrefix(name, "../"):
+		return "path is not canonical"
 	}
+
+	return ""
 }
 
-func downloadZip(url string) (*zip.Reader, error) {
-	// Download the ZIP file
-	resp, err := http.Get(url)
+// suspiciousLocation returns why a page can't be stored at the given
+// location, or an empty string if there is nothing wrong with it
+func suspiciousLocation(language, target, command string) string {
+	switch {
+	case language == "":
+		return "empty language"
 
-	if err != nil {
-		return nil, err
+	// These would clash with the other top level buckets
+	case language == string(defaultBucket), language == string(metaBucket):
+		return "reserved language name"
+
+	case target == "":
+		return "empty platform"
+
+	case command == "":
+		return "empty command"
 	}
 
-	// Read the entire body into a byte array
-	zipFile, err := ioutil.ReadAll(resp.Body)
+	return ""
+}
 
-	// Close the body
-	resp.Body.Close()
+// locatePage determines where the file at the given path in a source belongs,
+// english pages are found at pages/<target>/<command>.md and translations at
+// pages.<language>/<target>/<command>.md, other files are not pages.
+func locatePage(name string) (language, target, command string, ok bool) {
+	split := strings.Split(name, "/")
 
-	if err != nil {
-		return nil, err
+	// Is it a markdown file at the right depth?
+	if len(split) != 3 || !strings.HasSuffix(split[2], ".md") {
+		return "", "", "", false
 	}
 
-	// Turn this array into a zip reader
-	zipReader, err := zip.NewReader(
-		bytes.NewReader(zipFile),
-		int64(len(zipFile)),
-	)
+	// Detect language
+	switch {
+	// Is it the default: English?
+	case split[0] == "pages":
+		language = English
 
-	if err != nil {
-		return nil, err
+	// Else extract the language code
+	case strings.HasPrefix(split[0], "pages."):
+		language = strings.TrimPrefix(split[0], "pages.")
+
+		// English pages belong in the pages directory
+		if language == English {
+			return "", "", "", false
+		}
+
+	default:
+		return "", "", "", false
 	}
 
-	return zipReader, nil
+	target = split[1]
+	command = strings.TrimSuffix(split[2], ".md")
+
+	return language, target, command, true
 }
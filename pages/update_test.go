@@ -0,0 +1,510 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.etcd.io/bbolt"
+)
+
+const lsPage = "# ls\n\n> List files.\n\n- List:\n\n`ls`\n"
+
+// tempDir creates a temporary directory which is removed after the test
+func tempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "tldr-test")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// writeFile writes a file to the given slash separated path in the
+// directory, creating the directories in between, and returns its path
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	err := os.MkdirAll(filepath.Dir(path), 0777)
+
+	if err == nil {
+		err = ioutil.WriteFile(path, data, 0666)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// makeZip returns a zip archive containing the given files
+func makeZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	for name, contents := range files {
+		w, err := archive.Create(name)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w.Write([]byte(contents))
+	}
+
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// writeZip writes a zip archive with the given files to a temporary
+// directory and returns its path
+func writeZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	return writeFile(t, tempDir(t), "tldr.zip", makeZip(t, files))
+}
+
+// storedPages returns the names of all pages in the database at the given
+// path as language/platform/command
+func storedPages(t *testing.T, dbPath string) map[string]bool {
+	t.Helper()
+
+	database, err := bbolt.Open(dbPath, 0600, &bbolt.Options{ReadOnly: true})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer database.Close()
+
+	stored := make(map[string]bool)
+
+	err = database.View(
+		func(tx *bbolt.Tx) error {
+			return tx.ForEach(
+				func(language []byte, bucket *bbolt.Bucket) error {
+					return bucket.ForEach(
+						func(platform, value []byte) error {
+							if value != nil {
+								return nil
+							}
+
+							return bucket.Bucket(platform).ForEach(
+								func(command, _ []byte) error {
+									stored[string(language)+"/"+string(platform)+"/"+string(command)] = true
+									return nil
+								})
+						})
+				})
+		})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return stored
+}
+
+func TestImportDirectory(t *testing.T) {
+	dir := tempDir(t)
+	writeFile(t, dir, "pages/common/ls.md", []byte(lsPage))
+	writeFile(t, dir, "pages/linux/apt.md", []byte("# apt\n"))
+	writeFile(t, dir, "pages.fr/common/ls.md", []byte("# ls\n"))
+	writeFile(t, dir, "README.md", []byte("# tldr-pages\n"))
+
+	// Links could point anywhere, so they are skipped
+	secret := writeFile(t, tempDir(t), "secret.md", []byte("# secret\n"))
+	err := os.Symlink(secret, filepath.Join(dir, "pages", "common", "secret.md"))
+
+	if err != nil {
+		t.Skip("can't create symbolic links:", err)
+	}
+
+	dbPath := filepath.Join(tempDir(t), "tldr.bbolt")
+
+	if _, err := Import(dbPath, dir, UpdateOptions{}); err != nil {
+		t.Fatal("import failed:", err)
+	}
+
+	stored := storedPages(t, dbPath)
+	want := []string{"pages/common/ls", "pages/linux/apt", "fr/common/ls"}
+
+	for _, page := range want {
+		if !stored[page] {
+			t.Errorf("%s is missing", page)
+		}
+	}
+
+	if len(stored) != len(want) {
+		t.Errorf("got pages %v, want %v", stored, want)
+	}
+
+	// The link doesn't count as a file either
+	if _, err := Import(dbPath, dir, UpdateOptions{MaxEntries: 4}); err != nil {
+		t.Error("import of 4 files with a limit of 4 failed:", err)
+	}
+
+	if _, err := Import(dbPath, dir, UpdateOptions{MaxEntries: 3}); err == nil {
+		t.Error("import of 4 files with a limit of 3 succeeded")
+	}
+}
+
+func TestImportSkipsBadLocations(t *testing.T) {
+	page := "# ls\n\n> List files.\n\n- List:\n\n`ls`\n"
+
+	path := writeZip(t, map[string]string{
+		"pages/common/ls.md":       page,
+		"pages.fr/common/ls.md":    page,
+		"pages./common/ls.md":      page,
+		"pages.meta/common/ls.md":  page,
+		"pages.pages/common/ls.md": page,
+		"pages.en/common/ls.md":    page,
+		"pages//ls.md":             page,
+		"pages/common/.md":         page,
+		"../pages/common/evil.md":  page,
+	})
+
+	dbPath := filepath.Join(tempDir(t), "tldr.bbolt")
+	skipped, err := Import(dbPath, path, UpdateOptions{})
+
+	if err != nil {
+		t.Fatal("import failed:", err)
+	}
+
+	want := map[string]bool{
+		"pages./common/ls.md":      true,
+		"pages.meta/common/ls.md":  true,
+		"pages.pages/common/ls.md": true,
+		"pages//ls.md":             true,
+		"pages/common/.md":         true,
+		"../pages/common/evil.md":  true,
+	}
+
+	for _, file := range skipped {
+		if !want[file.Name] {
+			t.Errorf("unexpectedly skipped %s: %s", file.Name, file.Reason)
+		}
+
+		delete(want, file.Name)
+	}
+
+	for name := range want {
+		t.Errorf("%s was not skipped", name)
+	}
+
+	client, err := Open(dbPath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+	client.Platform = Common
+
+	languages, err := client.Languages()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(languages) != 2 || languages[0] != English || languages[1] != "fr" {
+		t.Errorf("got languages %v, want [en fr]", languages)
+	}
+
+	if _, _, err := client.Lookup("ls"); err != nil {
+		t.Error("lookup failed:", err)
+	}
+}
+
+func TestLocatePage(t *testing.T) {
+	tests := []struct {
+		name                      string
+		language, target, command string
+		ok                        bool
+	}{
+		{"pages/common/ls.md", English, "common", "ls", true},
+		{"pages.de/linux/apt.md", "de", "linux", "apt", true},
+		{"pages.en/linux/apt.md", "", "", "", false},
+		{"pages/common/ls.txt", "", "", "", false},
+		{"README.md", "", "", "", false},
+		{"other/common/ls.md", "", "", "", false},
+	}
+
+	for _, test := range tests {
+		language, target, command, ok := locatePage(test.name)
+
+		if language != test.language || target != test.target || command != test.command || ok != test.ok {
+			t.Errorf("locatePage(%q) = %q, %q, %q, %v", test.name, language, target, command, ok)
+		}
+	}
+}
+
+func TestFailedRebuildKeepsDatabase(t *testing.T) {
+	dir := tempDir(t)
+	dbPath := filepath.Join(dir, "tldr.bbolt")
+
+	_, err := Import(dbPath, writeZip(t, map[string]string{"pages/common/ls.md": lsPage}), UpdateOptions{})
+
+	if err != nil {
+		t.Fatal("import failed:", err)
+	}
+
+	archive := writeZip(t, map[string]string{"pages/common/tar.md": "# tar\n"})
+	broken := writeFile(t, tempDir(t), "tldr.zip", []byte("PK garbage"))
+	checksum := writeFile(t, tempDir(t), "tldr.zip.sha256", []byte(strings.Repeat("0", 64)+"  tldr.zip\n"))
+
+	failing := []struct {
+		location string
+		options  UpdateOptions
+	}{
+		{broken, UpdateOptions{}},
+		{archive, UpdateOptions{Checksum: checksum}},
+	}
+
+	for _, update := range failing {
+		if _, err := Update(dbPath, []string{update.location}, update.options); err == nil {
+			t.Fatalf("update from %s with %+v succeeded", update.location, update.options)
+		}
+
+		if stored := storedPages(t, dbPath); !stored["pages/common/ls"] || stored["pages/common/tar"] {
+			t.Errorf("the database changed to %v", stored)
+		}
+
+		leftovers, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+
+		if len(leftovers) != 0 {
+			t.Errorf("temporary files were left behind: %v", leftovers)
+		}
+	}
+}
+
+func TestMirrors(t *testing.T) {
+	archive := makeZip(t, map[string]string{"pages/common/ls.md": lsPage})
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/broken/tldr.zip":
+				w.WriteHeader(http.StatusInternalServerError)
+
+			case "/working/tldr.zip":
+				w.Write(archive)
+
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+
+	defer server.Close()
+
+	broken := server.URL + "/broken/tldr.zip"
+	missing := server.URL + "/missing/tldr.zip"
+	working := server.URL + "/working/tldr.zip"
+
+	dbPath := filepath.Join(tempDir(t), "tldr.bbolt")
+	options := UpdateOptions{Retries: -1}
+
+	if _, err := Update(dbPath, []string{broken, missing, working}, options); err != nil {
+		t.Fatal("update failed:", err)
+	}
+
+	meta, err := loadMetadata(dbPath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if meta[metaSource] != working {
+		t.Errorf("pages came from %s, want %s", meta[metaSource], working)
+	}
+
+	// All failures are reported
+	_, err = Update(dbPath, []string{broken, missing}, options)
+
+	if err == nil {
+		t.Fatal("update without a working mirror succeeded")
+	}
+
+	for _, location := range []string{broken, missing} {
+		if !strings.Contains(err.Error(), location) {
+			t.Errorf("error doesn't mention %s: %v", location, err)
+		}
+	}
+}
+
+func TestConditionalUpdate(t *testing.T) {
+	archive := makeZip(t, map[string]string{
+		"pages/common/ls.md": "# ls\n\n> List files.\n\n- List:\n\n`ls`\n",
+	})
+
+	downloads, notModified := 0, 0
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			downloads++
+			w.Header().Set("ETag", `"v1"`)
+			w.Write(archive)
+		}))
+
+	defer server.Close()
+
+	dbPath := filepath.Join(tempDir(t), "tldr.bbolt")
+	sources := []string{server.URL + "/tldr.zip"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := Update(dbPath, sources, UpdateOptions{Retries: -1}); err != nil {
+			t.Fatal("update failed:", err)
+		}
+	}
+
+	if downloads != 1 || notModified != 1 {
+		t.Errorf("got %d downloads and %d 304s, want 1 of each", downloads, notModified)
+	}
+
+	// A database we can't read is rebuilt even if nothing changed
+	err := storeMetadata(dbPath, metadata{metaSchema: "99"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Update(dbPath, sources, UpdateOptions{Retries: -1}); err != nil {
+		t.Fatal("update failed:", err)
+	}
+
+	meta, err := loadMetadata(dbPath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if meta[metaSchema] != strconv.Itoa(SchemaVersion) {
+		t.Errorf("schema is %s after the rebuild, want %d", meta[metaSchema], SchemaVersion)
+	}
+
+	if downloads != 2 {
+		t.Errorf("got %d downloads, want 2", downloads)
+	}
+}
+
+func TestFailedUpdateIsRecorded(t *testing.T) {
+	path := writeZip(t, map[string]string{
+		"pages/common/ls.md": "# ls\n\n> List files.\n\n- List:\n\n`ls`\n",
+	})
+
+	dbPath := filepath.Join(tempDir(t), "tldr.bbolt")
+
+	if _, err := Import(dbPath, path, UpdateOptions{}); err != nil {
+		t.Fatal("import failed:", err)
+	}
+
+	updated, _ := UpdatedAt(dbPath)
+
+	// Nothing listens on the server once it is closed
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	if _, err := Update(dbPath, []string{server.URL + "/tldr.zip"}, UpdateOptions{Retries: -1}); err == nil {
+		t.Fatal("update from a closed server succeeded")
+	}
+
+	checked, err := CheckedAt(dbPath)
+
+	if err != nil || checked.IsZero() {
+		t.Errorf("failed attempt was not recorded: %v", err)
+	}
+
+	if after, _ := UpdatedAt(dbPath); !after.Equal(updated) {
+		t.Errorf("update time changed from %v to %v", updated, after)
+	}
+}
+
+func TestRebuildCorruptDatabase(t *testing.T) {
+	archive := makeZip(t, map[string]string{"pages/common/ls.md": lsPage})
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write(archive)
+		}))
+
+	defer server.Close()
+
+	path := writeFile(t, tempDir(t), "tldr.zip", archive)
+	dbPath := filepath.Join(tempDir(t), "tldr.bbolt")
+
+	updates := map[string]func() ([]SkippedFile, error){
+		"Import": func() ([]SkippedFile, error) { return Import(dbPath, path, UpdateOptions{}) },
+		"Update": func() ([]SkippedFile, error) {
+			return Update(dbPath, []string{server.URL + "/tldr.zip"}, UpdateOptions{Retries: -1})
+		},
+	}
+
+	for name, update := range updates {
+		writeFile(t, filepath.Dir(dbPath), filepath.Base(dbPath), bytes.Repeat([]byte("garbage "), 1024))
+
+		if _, err := update(); err != nil {
+			t.Errorf("%s over a corrupt database failed: %v", name, err)
+			continue
+		}
+
+		if !storedPages(t, dbPath)["pages/common/ls"] {
+			t.Errorf("%s over a corrupt database lost the pages", name)
+		}
+	}
+}
+
+func TestUpdatedFrom(t *testing.T) {
+	dbPath := filepath.Join(tempDir(t), "tldr.bbolt")
+
+	if source, err := UpdatedFrom(dbPath); err != nil || source != "" {
+		t.Errorf("UpdatedFrom() without a database = %q, %v", source, err)
+	}
+
+	path := writeZip(t, map[string]string{"pages/common/ls.md": lsPage})
+
+	if _, err := Import(dbPath, path, UpdateOptions{}); err != nil {
+		t.Fatal("import failed:", err)
+	}
+
+	if source, err := UpdatedFrom(dbPath); err != nil || source != path {
+		t.Errorf("UpdatedFrom() = %q, %v, want %q", source, err, path)
+	}
+}
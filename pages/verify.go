@@ -0,0 +1,145 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// verify checks the archive with the given file name against the checksum
+// and signature configured in the options
+func verify(archive []byte, name string, options UpdateOptions) error {
+	if options.Checksum != "" {
+		sums, err := fetch(options.Checksum, options)
+
+		if err != nil {
+			return err
+		}
+
+		expected, err := findChecksum(sums, name)
+
+		if err != nil {
+			return err
+		}
+
+		actual := sha256.Sum256(archive)
+
+		if !strings.EqualFold(expected, hex.EncodeToString(actual[:])) {
+			return fmt.Errorf("checksum mismatch for %s: expected %s, got %x", name, expected, actual)
+		}
+	}
+
+	// Both are needed to check a signature
+	if options.PublicKey == "" && options.Signature == "" {
+		return nil
+	}
+
+	if options.PublicKey == "" {
+		return errors.New("a public key is needed to verify the signature")
+	}
+
+	if options.Signature == "" {
+		return errors.New("a signature is needed to verify against the public key")
+	}
+
+	key, err := decodeKey(options.PublicKey, ed25519.PublicKeySize)
+
+	if err != nil {
+		return fmt.Errorf("invalid public key: %v", err)
+	}
+
+	signature, err := fetch(options.Signature, options)
+
+	if err != nil {
+		return err
+	}
+
+	// Signatures are either stored raw or encoded
+	if len(signature) != ed25519.SignatureSize {
+		signature, err = decodeKey(string(signature), ed25519.SignatureSize)
+
+		if err != nil {
+			return fmt.Errorf("invalid signature: %v", err)
+		}
+	}
+
+	if !ed25519.Verify(key, archive, signature) {
+		return errors.New("invalid signature for " + name)
+	}
+
+	return nil
+}
+
+// findChecksum looks up the checksum of the given file in the output of
+// sha256sum, a file with a single checksum applies to any file name
+func findChecksum(sums []byte, name string) (string, error) {
+	var found []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		// A lone checksum
+		if len(fields) == 1 {
+			found = append(found, fields[0])
+			continue
+		}
+
+		// The file name is marked with a * in binary mode
+		if strings.TrimPrefix(fields[1], "*") == name {
+			return fields[0], nil
+		}
+	}
+
+	if len(found) == 1 {
+		return found[0], nil
+	}
+
+	return "", errors.New("no checksum found for " + name)
+}
+
+// decodeKey decodes a hex or base64 encoded key or signature of the given size
+func decodeKey(encoded string, size int) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+
+	key, err := hex.DecodeString(encoded)
+
+	if err != nil {
+		key, err = base64.StdEncoding.DecodeString(encoded)
+	}
+
+	if err != nil {
+		return nil, errors.New("expected hex or base64")
+	}
+
+	if len(key) != size {
+		return nil, fmt.Errorf("expected %d bytes, got %d", size, len(key))
+	}
+
+	return key, nil
+}
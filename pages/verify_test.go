@@ -0,0 +1,121 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+func TestFindChecksum(t *testing.T) {
+	tests := []struct {
+		sums  string
+		name  string
+		found string
+	}{
+		{"abc  tldr.zip\n", "tldr.zip", "abc"},
+		{"abc *tldr.zip\n", "tldr.zip", "abc"},
+		{"abc  other.zip\ndef  tldr.zip\n", "tldr.zip", "def"},
+		{"abc\n", "tldr.zip", "abc"},
+		{"\nabc\n\n", "anything.zip", "abc"},
+		{"abc  other.zip\n", "tldr.zip", ""},
+		{"abc\ndef\n", "tldr.zip", ""},
+		{"", "tldr.zip", ""},
+	}
+
+	for _, test := range tests {
+		found, err := findChecksum([]byte(test.sums), test.name)
+
+		if found != test.found || (err == nil) != (test.found != "") {
+			t.Errorf("findChecksum(%q, %q) = %q, %v, want %q", test.sums, test.name, found, err, test.found)
+		}
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	dir := tempDir(t)
+	archive := []byte("some archive")
+	sum := sha256.Sum256(archive)
+
+	good := writeFile(t, dir, "good.sha256", []byte(hex.EncodeToString(sum[:])+"  tldr.zip\n"))
+	bad := writeFile(t, dir, "bad.sha256", []byte(hex.EncodeToString(make([]byte, sha256.Size))+"  tldr.zip\n"))
+
+	if err := verify(archive, "tldr.zip", UpdateOptions{Checksum: good}); err != nil {
+		t.Errorf("matching checksum rejected: %v", err)
+	}
+
+	if err := verify(archive, "tldr.zip", UpdateOptions{Checksum: bad}); err == nil {
+		t.Error("mismatched checksum accepted")
+	}
+
+	if err := verify(archive, "other.zip", UpdateOptions{Checksum: good}); err == nil {
+		t.Error("checksum of another file accepted")
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	dir := tempDir(t)
+	archive := []byte("some archive")
+
+	public, private, err := ed25519.GenerateKey(nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature := ed25519.Sign(private, archive)
+	key := hex.EncodeToString(public)
+
+	signatures := map[string][]byte{
+		"raw":    signature,
+		"hex":    []byte(hex.EncodeToString(signature) + "\n"),
+		"base64": []byte(base64.StdEncoding.EncodeToString(signature) + "\n"),
+	}
+
+	for encoding, data := range signatures {
+		path := writeFile(t, dir, encoding+".sig", data)
+		options := UpdateOptions{PublicKey: key, Signature: path}
+
+		if err := verify(archive, "tldr.zip", options); err != nil {
+			t.Errorf("%s signature rejected: %v", encoding, err)
+		}
+
+		if err := verify([]byte("another archive"), "tldr.zip", options); err == nil {
+			t.Errorf("%s signature accepted for another archive", encoding)
+		}
+	}
+
+	path := writeFile(t, dir, "tldr.sig", signature)
+	otherPublic, _, _ := ed25519.GenerateKey(nil)
+
+	invalid := []UpdateOptions{
+		{PublicKey: base64.StdEncoding.EncodeToString(otherPublic), Signature: path},
+		{PublicKey: key},
+		{Signature: path},
+		{PublicKey: "not a key", Signature: path},
+		{PublicKey: key[2:], Signature: path},
+		{PublicKey: key, Signature: writeFile(t, dir, "short.sig", []byte("abcd"))},
+	}
+
+	for _, options := range invalid {
+		if err := verify(archive, "tldr.zip", options); err == nil {
+			t.Errorf("verify with %+v succeeded", options)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// segment is a piece of text in a single style
+type segment struct {
+	text  string
+	style Style
+
+	// whole is set if the segment should not be broken up at its spaces
+	whole bool
+}
+
+// word is text which can't be broken up when wrapping,
+// together with the spaces in front of it
+type word struct {
+	space    []segment
+	segments []segment
+}
+
+// segmentsWidth returns how many columns the segments take up
+func segmentsWidth(segments []segment) int {
+	width := 0
+
+	for _, seg := range segments {
+		width += utf8.RuneCountInString(seg.text)
+	}
+
+	return width
+}
+
+// splitWords splits the segments at spaces, a word can consist of
+// segments in different styles, like a placeholder followed by a dot
+func splitWords(segments []segment) []word {
+	var words []word
+	var current word
+
+	for _, seg := range segments {
+		if seg.whole && !strings.HasPrefix(seg.text, " ") {
+			current.segments = append(current.segments, seg)
+			continue
+		}
+
+		text := seg.text
+
+		for text != "" {
+			if text[0] == ' ' {
+				// A space ends the current word
+				if current.segments != nil {
+					words = append(words, current)
+					current = word{}
+				}
+
+				n := len(text) - len(strings.TrimLeft(text, " "))
+				current.space = append(current.space, segment{text[:n], seg.style, false})
+				text = text[n:]
+				continue
+			}
+
+			n := strings.IndexByte(text, ' ')
+
+			if n < 0 {
+				n = len(text)
+			}
+
+			current.segments = append(current.segments, segment{text[:n], seg.style, false})
+			text = text[n:]
+		}
+	}
+
+	if current.space != nil || current.segments != nil {
+		words = append(words, current)
+	}
+
+	return words
+}
+
+// printWrapped prints the segments preceded by indent, if they don't fit
+// in the width of the printer they are wrapped at spaces and continued on
+// the next lines after hangingIndent. Every line but the last then ends in
+// marker, which is also taken into account when wrapping.
+func (p *prettyPrinter) printWrapped(segments []segment, indent, hangingIndent, marker string) {
+	p.print(indent, Style{})
+	column := utf8.RuneCountInString(indent)
+
+	// Don't wrap what fits or if we don't know the width
+	width := p.options.Width
+
+	if width <= 0 || column+segmentsWidth(segments) <= width {
+		for _, seg := range segments {
+			p.print(seg.text, seg.style)
+		}
+
+		return
+	}
+
+	width -= utf8.RuneCountInString(marker)
+	empty := true
+
+	for _, w := range splitWords(segments) {
+		spaceWidth := segmentsWidth(w.space)
+		wordWidth := segmentsWidth(w.segments)
+
+		if !empty && column+spaceWidth+wordWidth > width {
+			// Continue on the next line, the spaces are dropped
+			p.print(marker+"\n"+hangingIndent, Style{})
+			column = utf8.RuneCountInString(hangingIndent)
+
+		} else {
+			for _, seg := range w.space {
+				p.print(seg.text, seg.style)
+			}
+
+			column += spaceWidth
+		}
+
+		for _, seg := range w.segments {
+			p.print(seg.text, seg.style)
+		}
+
+		column += wordWidth
+		empty = empty && w.segments == nil
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright © 2020 Evert Provoost
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package pages
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+const wrapPage = "# tar\n\n" +
+	"> Archiving utility which is often combined with a compression method.\n\n" +
+	"- Create an archive from files and directories:\n\n" +
+	"`tar cf {{path/to/target.tar}} {{path/to/file1 path/to/file2}}`\n"
+
+func TestPrettyPrintWidth(t *testing.T) {
+	tests := []struct {
+		width int
+		want  string
+	}{
+		{0, "\n  tar\n" +
+			"  Archiving utility which is often combined with a compression method.\n\n" +
+			"- Create an archive from files and directories:\n" +
+			"  tar cf path/to/target.tar path/to/file1 path/to/file2\n\n"},
+
+		{30, "\n  tar\n" +
+			"  Archiving utility which is\n" +
+			"  often combined with a\n" +
+			"  compression method.\n\n" +
+			"- Create an archive from files\n" +
+			"  and directories:\n" +
+			"  tar cf path/to/target.tar \\\n" +
+			"      path/to/file1 path/to/file2\n\n"},
+
+		// Placeholders are never broken up, even if they don't fit
+		{20, "\n  tar\n" +
+			"  Archiving utility\n" +
+			"  which is often\n" +
+			"  combined with a\n" +
+			"  compression\n" +
+			"  method.\n\n" +
+			"- Create an archive\n" +
+			"  from files and\n" +
+			"  directories:\n" +
+			"  tar cf \\\n" +
+			"      path/to/target.tar \\\n" +
+			"      path/to/file1 path/to/file2\n\n"},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		PrettyPrint(&out, []byte(wrapPage), PrettyPrintOptions{Width: test.width})
+
+		if out.String() != test.want {
+			t.Errorf("PrettyPrint with width %d:\n%s\nwant:\n%s", test.width, out.String(), test.want)
+		}
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	segments := []segment{
+		{"cp ", Style{}, false},
+		{"path/to/some file", Style{}, true},
+		{". and  more", Style{}, false},
+	}
+
+	var words []string
+
+	for _, w := range splitWords(segments) {
+		var text string
+
+		for _, seg := range append(w.space, w.segments...) {
+			text += seg.text
+		}
+
+		words = append(words, text)
+	}
+
+	want := []string{"cp", " path/to/some file.", " and", "  more"}
+
+	if !reflect.DeepEqual(words, want) {
+		t.Errorf("splitWords() = %q, want %q", words, want)
+	}
+}